@@ -0,0 +1,158 @@
+package steam
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// DescriptionCache stores item descriptions across inventory loads,
+// descriptions never change so entries do not need to expire.
+// Keys are opaque and unique per app, class, instance and language.
+type DescriptionCache interface {
+	Get(key string) (*EconItemDesc, bool)
+	Set(key string, desc *EconItemDesc)
+}
+
+// LRUDescriptionCache is a DescriptionCache holding up to a fixed amount of
+// descriptions, evicting the least recently used ones.  It is safe for concurrent use.
+type LRUDescriptionCache struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key  string
+	desc *EconItemDesc
+}
+
+func NewLRUDescriptionCache(size int) *LRUDescriptionCache {
+	if size < 1 {
+		size = 1
+	}
+
+	return &LRUDescriptionCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUDescriptionCache) Get(key string) (*EconItemDesc, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).desc, true
+}
+
+func (c *LRUDescriptionCache) Set(key string, desc *EconItemDesc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).desc = desc
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key, desc})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+}
+
+// Len returns the amount of cached descriptions.
+func (c *LRUDescriptionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len()
+}
+
+type priceOverviewKey struct {
+	appID          uint64
+	currencyID     string
+	country        string
+	marketHashName string
+}
+
+type priceOverviewEntry struct {
+	overview MarketItemPriceOverview
+	expires  time.Time
+}
+
+// SetPriceOverviewCacheTTL makes GetMarketItemPriceOverview reuse successful responses
+// for ttl instead of asking Steam again.  Zero, the default, disables the cache and empties it.
+func (session *Session) SetPriceOverviewCacheTTL(ttl time.Duration) {
+	session.priceOverviewsMu.Lock()
+	defer session.priceOverviewsMu.Unlock()
+
+	session.priceOverviewTTL = ttl
+	if ttl <= 0 {
+		session.priceOverviews = nil
+	}
+}
+
+// InvalidatePriceOverview drops the cached price overviews of an item, in every currency.
+func (session *Session) InvalidatePriceOverview(appID uint64, marketHashName string) {
+	session.priceOverviewsMu.Lock()
+	defer session.priceOverviewsMu.Unlock()
+
+	for key := range session.priceOverviews {
+		if key.appID == appID && key.marketHashName == marketHashName {
+			delete(session.priceOverviews, key)
+		}
+	}
+}
+
+// ClearPriceOverviewCache drops every cached price overview.
+func (session *Session) ClearPriceOverviewCache() {
+	session.priceOverviewsMu.Lock()
+	defer session.priceOverviewsMu.Unlock()
+
+	session.priceOverviews = nil
+}
+
+func (session *Session) cachedPriceOverview(key priceOverviewKey) (*MarketItemPriceOverview, bool) {
+	session.priceOverviewsMu.Lock()
+	defer session.priceOverviewsMu.Unlock()
+
+	entry, ok := session.priceOverviews[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.expires) {
+		delete(session.priceOverviews, key)
+		return nil, false
+	}
+
+	/* Callers get their own copy to modify.  */
+	overview := entry.overview
+	return &overview, true
+}
+
+func (session *Session) cachePriceOverview(key priceOverviewKey, overview *MarketItemPriceOverview) {
+	session.priceOverviewsMu.Lock()
+	defer session.priceOverviewsMu.Unlock()
+
+	if session.priceOverviewTTL <= 0 || !overview.Success {
+		return
+	}
+
+	if session.priceOverviews == nil {
+		session.priceOverviews = make(map[priceOverviewKey]priceOverviewEntry)
+	}
+
+	session.priceOverviews[key] = priceOverviewEntry{*overview, time.Now().Add(session.priceOverviewTTL)}
+}
@@ -90,7 +90,7 @@ type ChatFriendResponse struct {
 }
 
 func (session *Session) ChatLogin(uiMode string) error {
-	resp, err := session.client.PostForm(apiUserPresenceLogin, url.Values{
+	resp, err := session.httpClient().PostForm(apiUserPresenceLogin, url.Values{
 		"ui_mode":      {uiMode},
 		"access_token": {session.oauth.Token},
 	})
@@ -121,7 +121,7 @@ func (session *Session) ChatLogin(uiMode string) error {
 }
 
 func (session *Session) ChatLogoff() error {
-	resp, err := session.client.PostForm(apiUserPresenceLogoff, url.Values{
+	resp, err := session.httpClient().PostForm(apiUserPresenceLogoff, url.Values{
 		"access_token": {session.oauth.Token},
 		"umqid":        {session.umqID},
 	})
@@ -141,7 +141,7 @@ func (session *Session) ChatLogoff() error {
 }
 
 func (session *Session) ChatSendMessage(sid SteamID, message, messageType string) error {
-	resp, err := session.client.PostForm(apiUserPresenceMessage, url.Values{
+	resp, err := session.httpClient().PostForm(apiUserPresenceMessage, url.Values{
 		"access_token": {session.oauth.Token},
 		"steamid_dst":  {sid.ToString()},
 		"text":         {message},
@@ -169,7 +169,7 @@ func (session *Session) ChatSendMessage(sid SteamID, message, messageType string
 }
 
 func (session *Session) ChatPoll(timeoutSeconds string) (*ChatResponse, error) {
-	resp, err := session.client.PostForm(apiUserPresencePoll, url.Values{
+	resp, err := session.httpClient().PostForm(apiUserPresencePoll, url.Values{
 		"umqid":          {session.umqID},
 		"access_token":   {session.oauth.Token},
 		"message":        {strconv.FormatUint(uint64(session.chatMessage), 10)},
@@ -199,7 +199,7 @@ func (session *Session) ChatPoll(timeoutSeconds string) (*ChatResponse, error) {
 }
 
 func (session *Session) ChatFriendState(sid SteamID) (*ChatFriendResponse, error) {
-	resp, err := session.client.Get("https://steamcommunity.com/chat/friendstate/" + strconv.FormatUint(uint64(sid.GetAccountID()), 10))
+	resp, err := session.httpClient().Get("https://steamcommunity.com/chat/friendstate/" + strconv.FormatUint(uint64(sid.GetAccountID()), 10))
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -221,7 +221,7 @@ func (session *Session) ChatFriendState(sid SteamID) (*ChatFriendResponse, error
 }
 
 func (session *Session) ChatLog(partner uint32) ([]*ChatLogMessage, error) {
-	resp, err := session.client.PostForm(fmt.Sprintf("https://steamcommunity.com/chat/chatlog/%d", partner), url.Values{
+	resp, err := session.httpClient().PostForm(fmt.Sprintf("https://steamcommunity.com/chat/chatlog/%d", partner), url.Values{
 		"sessionid": {session.sessionID},
 	})
 	if resp != nil {
@@ -3,40 +3,113 @@ package steam
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 )
 
 type ConfirmationResponse struct {
 	Success       bool            `json:"success"`
 	Confirmations []*Confirmation `json:"conf"`
+	NeedAuth      bool            `json:"needauth"` // Set by ConfirmationsV2 when the session expired
+	Message       string          `json:"message"`
+	Detail        string          `json:"detail"`
 }
 
+// Versions of the mobileconf protocol, see SetConfirmationsVersion.
+const (
+	ConfirmationsV1 = iota // Query parameters only, the default
+	ConfirmationsV2        // As the current mobile app: its headers, and errors reported in the response
+)
+
+// mobileAppHeaders are sent by ConfirmationsV2 requests, as the Steam mobile app does.
+var mobileAppHeaders = http.Header{
+	"User-Agent":       {"Mozilla/5.0 (Linux; Android 12; Valve Steam App Version/3) AppleWebKit/537.36 (KHTML, like Gecko) Mobile Safari/537.36"},
+	"X-Requested-With": {"com.valvesoftware.android.steam.community"},
+	"Accept":           {"application/json, text/plain, */*"},
+}
+
+// SetConfirmationsVersion selects the mobileconf protocol FetchConfirmations speaks.
+// With ConfirmationsV2, the confirmation list is loaded again with ConfirmationsV1
+// when the newer request fails for another reason than an expired session.
+func (session *Session) SetConfirmationsVersion(version int) {
+	session.confirmationsVersion = version
+}
+
+// Confirmation types, as found in Confirmation.Type.
+const (
+	ConfirmationTypeTest              = 1
+	ConfirmationTypeTrade             = 2
+	ConfirmationTypeMarketListing     = 3
+	ConfirmationTypeFeatureOptOut     = 4
+	ConfirmationTypePhoneNumberChange = 5
+	ConfirmationTypeAccountRecovery   = 6
+)
+
 type Confirmation struct {
-	ID           string `json:"id"`
-	Type         uint8  `json:"type"`
-	Creator      string `json:"creator_id"`
-	Nonce        string `json:"nonce"`
-	CreationTime uint64 `json:"creation"`
+	ID           string   `json:"id"`
+	Type         uint8    `json:"type"`
+	TypeName     string   `json:"type_name"`  // Localized, e.g. "Trade Offer"
+	Creator      string   `json:"creator_id"` // Trade offer or listing ID
+	Nonce        string   `json:"nonce"`
+	CreationTime uint64   `json:"creation_time"`
+	Headline     string   `json:"headline"`
+	Summary      []string `json:"summary"`
+	Icon         string   `json:"icon"`
+	Multi        bool     `json:"multi"`
 
-	// TypeName string      `json:"type_name"`
 	// Cancel   string      `json:"cancel"`
 	// Accept   string      `json:"accept"`
-	// Icon     string      `json:"icon"`
-	// Multi    bool        `json:"multi"`
-	// Headline string      `json:"headline"`
-	// Summary  []string    `json:"summary"`
 	// Warn     interface{} `json:"warn"`
 }
 
+// Created returns the time the confirmation was created at.
+func (conf *Confirmation) Created() time.Time {
+	return time.Unix(int64(conf.CreationTime), 0)
+}
+
+// Answers to a confirmation, the tag of SendConfirmationAjax and SendMultiConfirmationAjax.
+const (
+	ConfirmationAccept = "accept"
+	ConfirmationReject = "reject"
+)
+
+// confirmationOp returns the mobileconf operation answering tag.
+func confirmationOp(tag string) (string, error) {
+	switch tag {
+	case ConfirmationAccept:
+		return "allow", nil
+	case ConfirmationReject:
+		return "cancel", nil
+	}
+
+	return "", fmt.Errorf("%w: %q", ErrInvalidConfirmationTag, tag)
+}
+
+// Accept accepts the confirmation conf.
+func (session *Session) Accept(conf *Confirmation, identitySecret string) (*ConfirmationAcceptResponse, error) {
+	return session.SendConfirmationAjax(conf, ConfirmationAccept, identitySecret)
+}
+
+// Reject cancels the action awaiting the confirmation conf, e.g. the trade offer or listing.
+func (session *Session) Reject(conf *Confirmation, identitySecret string) (*ConfirmationAcceptResponse, error) {
+	return session.SendConfirmationAjax(conf, ConfirmationReject, identitySecret)
+}
+
 var (
 	//ErrConfirmationsUnknownError = errors.New("unknown error occurred finding confirmation")
 	ErrCannotFindConfirmations   = errors.New("unable to find confirmation")
 	ErrCannotFindDescriptions    = errors.New("unable to find confirmation descriptions")
 	ErrConfirmationsDescMismatch = errors.New("cannot match confirmation with their respective descriptions")
 	ErrWGTokenExpired            = errors.New("WGToken expired")
+	ErrInvalidConfirmationTag    = errors.New("confirmation tag must be ConfirmationAccept or ConfirmationReject")
+
+	// ErrConfirmationDetailsNotFound is returned when the details of a confirmation
+	// do not tell which trade offer or listing it is for.
+	ErrConfirmationDetailsNotFound = errors.New("unable to find the trade offer or listing of the confirmation")
 )
 
 func (session *Session) execConfirmationRequest(request, key, tag string, current int64, values map[string]string) (*http.Response, error) {
@@ -53,7 +126,7 @@ func (session *Session) execConfirmationRequest(request, key, tag string, curren
 		params.Add(k, v)
 	}
 
-	return session.client.Get("https://steamcommunity.com/mobileconf/" + request + params.Encode())
+	return session.httpClient().Get("https://steamcommunity.com/mobileconf/" + request + params.Encode())
 }
 
 func (session *Session) GetConfirmations(identitySecret string, current int64) ([]*Confirmation, error) {
@@ -0,0 +1,47 @@
+package steam
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func newConfirmationDetailsSession(t *testing.T, page string) *Session {
+	t.Helper()
+
+	return newStubSession(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/QueryTime/") {
+			io.WriteString(w, `{"response": {"server_time": "1700000000"}}`)
+			return
+		}
+
+		io.WriteString(w, page)
+	}))
+}
+
+func TestGetConfirmationDetails(t *testing.T) {
+	/* The creator of trade and listing confirmations is all it takes, no request is sent.  */
+	session := newConfirmationDetailsSession(t, "")
+	details, err := session.GetConfirmationDetails(&Confirmation{ID: "1", Type: ConfirmationTypeTrade, Creator: "123"}, rfc4226Secret)
+	if err != nil || details.TradeOfferID != 123 {
+		t.Errorf("trade confirmation: got %+v, %v, want trade offer 123", details, err)
+	}
+
+	session = newConfirmationDetailsSession(t, `<div class="mylisting_456"></div>`)
+	details, err = session.GetConfirmationDetails(&Confirmation{ID: "1", Type: ConfirmationTypeMarketListing}, rfc4226Secret)
+	if err != nil || details.ListingID != 456 {
+		t.Errorf("listing details page: got %+v, %v, want listing 456", details, err)
+	}
+
+	session = newConfirmationDetailsSession(t, `<div>nothing to see</div>`)
+	if _, err = session.GetConfirmationDetails(&Confirmation{ID: "1"}, rfc4226Secret); !errors.Is(err, ErrConfirmationDetailsNotFound) {
+		t.Errorf("details without ID: got %v, want ErrConfirmationDetailsNotFound", err)
+	}
+
+	session = newConfirmationDetailsSession(t, `<div id="tradeofferid_99999999999999999999999"></div>`)
+	if details, err = session.GetConfirmationDetails(&Confirmation{ID: "1"}, rfc4226Secret); err == nil {
+		t.Errorf("overflowing trade offer ID: got %+v, want an error", details)
+	}
+}
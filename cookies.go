@@ -0,0 +1,108 @@
+package steam
+
+import (
+	"errors"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrNoLoginCookie  = errors.New("steamLoginSecure cookie is missing")
+	ErrCookiesExpired = errors.New("cookies do not hold a logged in session")
+)
+
+// cookieHosts are the hosts whose cookies make up a logged in session.
+var cookieHosts = []string{"steamcommunity.com", "login.steampowered.com"}
+
+// ExportCookies returns the session cookies, with their Domain set, so that the
+// session can be restored by ImportCookies.  http.Cookie can be stored as JSON.
+func (session *Session) ExportCookies() ([]*http.Cookie, error) {
+	if session.httpClient().Jar == nil {
+		return nil, ErrNoLoginCookie
+	}
+
+	cookies := []*http.Cookie{}
+	for _, host := range cookieHosts {
+		for _, cookie := range session.httpClient().Jar.Cookies(&url.URL{Scheme: "https", Host: host}) {
+			cookie.Domain = host
+			cookies = append(cookies, cookie)
+		}
+	}
+
+	return cookies, nil
+}
+
+// ImportCookies restores a session exported by ExportCookies, cookies without Domain
+// are set for steamcommunity.com. The SteamID and sessionid are taken from the cookies
+// and the session is then checked to still be logged in.
+func (session *Session) ImportCookies(cookies []*http.Cookie) error {
+	byHost := map[string][]*http.Cookie{}
+	var steamID SteamID
+	sessionID := ""
+	for _, cookie := range cookies {
+		host := strings.TrimPrefix(cookie.Domain, ".")
+		if host == "" {
+			host = "steamcommunity.com"
+		}
+
+		imported := &http.Cookie{Name: cookie.Name, Value: cookie.Value, Path: "/", Secure: true}
+		byHost[host] = append(byHost[host], imported)
+
+		if host != "steamcommunity.com" {
+			continue
+		}
+
+		switch cookie.Name {
+		case "sessionid":
+			sessionID = cookie.Value
+		case "steamLoginSecure":
+			/* "<STEAM_ID>||<TOKEN>", usually escaped.  */
+			value, err := url.QueryUnescape(cookie.Value)
+			if err != nil {
+				value = cookie.Value
+			}
+
+			id, err := strconv.ParseUint(strings.SplitN(value, "||", 2)[0], 10, 64)
+			if err != nil {
+				return ErrNoLoginCookie
+			}
+			steamID = SteamID(id)
+		}
+	}
+
+	if steamID == 0 {
+		return ErrNoLoginCookie
+	}
+
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+
+	for host, cookies := range byHost {
+		jar.SetCookies(&url.URL{Scheme: "https", Host: host}, cookies)
+	}
+
+	session.httpClient().Jar = jar
+	session.resetProfileURL()
+	session.resetWalletLocale()
+	session.oauth.SteamID = steamID
+	if sessionID != "" {
+		session.sessionID = sessionID
+	}
+	session.addMobileAuthCookies()
+
+	loggedIn, err := session.IsLoggedIn()
+	if err != nil {
+		return err
+	}
+
+	if !loggedIn {
+		return ErrCookiesExpired
+	}
+
+	return nil
+}
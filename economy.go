@@ -0,0 +1,248 @@
+package steam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	apiGetAssetClassInfo = APIBaseUrl + "/ISteamEconomy/GetAssetClassInfo/v1/?"
+
+	// EconomyImageURL is the CDN base of item icons, which descriptions only give the path of.
+	// The IconURLFull methods append the path to it, followed by the size when not empty,
+	// e.g. "96fx96f", the icon is served at its original size otherwise.
+	EconomyImageURL = "https://community.cloudflare.steamstatic.com/economy/image/"
+
+	// Steam rejects larger class_count values.
+	maxAssetClassInfoCount = 100
+)
+
+var (
+	ErrMissingAPIKey = errors.New("web API key is not set")
+
+	// ErrAssetClassInfo is returned when GetAssetClassInfo answers with an error.
+	ErrAssetClassInfo = errors.New("GetAssetClassInfo failed")
+)
+
+// ClassInstance identifies an item description.
+type ClassInstance struct {
+	ClassID    uint64
+	InstanceID uint64
+}
+
+// assetClassInfo is the description shape returned by GetAssetClassInfo,
+// numbers are sent as strings and lists as objects keyed by index.
+type assetClassInfo struct {
+	ClassID         uint64                 `json:"classid,string"`
+	InstanceID      uint64                 `json:"instanceid,string"`
+	Tradable        int                    `json:"tradable,string"`
+	Marketable      int                    `json:"marketable,string"`
+	BackgroundColor string                 `json:"background_color"`
+	IconURL         string                 `json:"icon_url"`
+	IconLargeURL    string                 `json:"icon_url_large"`
+	IconDragURL     string                 `json:"icon_drag_url"`
+	Name            string                 `json:"name"`
+	NameColor       string                 `json:"name_color"`
+	MarketName      string                 `json:"market_name"`
+	MarketHashName  string                 `json:"market_hash_name"`
+	MarketFeeApp    uint32                 `json:"market_fee_app,string"`
+	Commodity       string                 `json:"commodity"`
+	Actions         map[string]*EconAction `json:"actions"`
+	Descriptions    map[string]*EconDesc   `json:"descriptions"`
+	Tags            map[string]struct {
+		InternalName string `json:"internal_name"`
+		Name         string `json:"name"`
+		Category     string `json:"category"`
+		CategoryName string `json:"category_name"`
+	} `json:"tags"`
+}
+
+func (info *assetClassInfo) toEconItemDesc() *EconItemDesc {
+	desc := &EconItemDesc{
+		ClassID:         info.ClassID,
+		InstanceID:      info.InstanceID,
+		Tradable:        info.Tradable,
+		Marketable:      info.Marketable,
+		BackgroundColor: info.BackgroundColor,
+		IconURL:         info.IconURL,
+		IconLargeURL:    info.IconLargeURL,
+		IconDragURL:     info.IconDragURL,
+		Name:            info.Name,
+		NameColor:       info.NameColor,
+		MarketName:      info.MarketName,
+		MarketHashName:  info.MarketHashName,
+		MarketFeeApp:    info.MarketFeeApp,
+		Comodity:        info.Commodity == "1",
+	}
+
+	/* Objects are keyed by "0", "1", ... keep Steam's order.  */
+	for i := 0; i < len(info.Actions); i++ {
+		if action, ok := info.Actions[strconv.Itoa(i)]; ok {
+			desc.Actions = append(desc.Actions, action)
+		}
+	}
+
+	for i := 0; i < len(info.Descriptions); i++ {
+		if d, ok := info.Descriptions[strconv.Itoa(i)]; ok {
+			desc.Descriptions = append(desc.Descriptions, d)
+		}
+	}
+
+	for i := 0; i < len(info.Tags); i++ {
+		if tag, ok := info.Tags[strconv.Itoa(i)]; ok {
+			desc.Tags = append(desc.Tags, &EconTag{
+				InternalName:          tag.InternalName,
+				Category:              tag.Category,
+				LocalizedCategoryName: tag.CategoryName,
+				LocalizedTagName:      tag.Name,
+			})
+		}
+	}
+
+	return desc
+}
+
+// fetchAssetClassInfo returns descriptions keyed by "<CLASS_ID>_<INSTANCE_ID>".
+func (session *Session) fetchAssetClassInfo(ctx context.Context, apiKey string, appID uint64, language string, pairs []ClassInstance) (map[string]*EconItemDesc, error) {
+	if apiKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	params := url.Values{
+		"key":         {apiKey},
+		"appid":       {strconv.FormatUint(appID, 10)},
+		"language":    {language},
+		"class_count": {strconv.Itoa(len(pairs))},
+	}
+	for i, pair := range pairs {
+		params.Set("classid"+strconv.Itoa(i), strconv.FormatUint(pair.ClassID, 10))
+		params.Set("instanceid"+strconv.Itoa(i), strconv.FormatUint(pair.InstanceID, 10))
+	}
+
+	type Response struct {
+		Inner map[string]json.RawMessage `json:"result"`
+	}
+
+	var response Response
+	if err := session.getJSON(ctx, http.MethodGet, apiGetAssetClassInfo+params.Encode(), nil, "", &response); err != nil {
+		return nil, err
+	}
+
+	descriptions := make(map[string]*EconItemDesc)
+	for key, raw := range response.Inner {
+		switch key {
+		case "success":
+			continue
+		case "error":
+			var msg string
+			if err := json.Unmarshal(raw, &msg); err != nil || msg == "" {
+				return nil, ErrAssetClassInfo
+			}
+
+			return nil, fmt.Errorf("%w: %s", ErrAssetClassInfo, msg)
+		}
+
+		var info assetClassInfo
+		if err := json.Unmarshal(raw, &info); err != nil {
+			return nil, err
+		}
+
+		/* Keys are "<CLASS_ID>_<INSTANCE_ID>", or just "<CLASS_ID>" for instance 0.  */
+		if !strings.Contains(key, "_") {
+			key += "_0"
+		}
+		descriptions[key] = info.toEconItemDesc()
+	}
+
+	return descriptions, nil
+}
+
+// GetAssetDescriptions returns the descriptions of pairs from the GetAssetClassInfo Web API,
+// keyed by "<CLASS_ID>_<INSTANCE_ID>". It requires the session's Web API key.
+func (session *Session) GetAssetDescriptions(appID uint64, pairs []ClassInstance) (map[string]*EconItemDesc, error) {
+	return session.getAssetDescriptions(context.Background(), session.apiKey, appID, session.language, pairs)
+}
+
+// GetAssetClassInfo returns the descriptions of classIDs, all of instance 0, from the
+// GetAssetClassInfo Web API using apiKey, keyed by class ID.  An empty language
+// stands for the session language.
+func (session *Session) GetAssetClassInfo(apiKey string, appID uint64, classIDs []uint64, language string) (map[uint64]*EconItemDesc, error) {
+	if language == "" {
+		language = session.language
+	}
+
+	pairs := make([]ClassInstance, len(classIDs))
+	for i, classID := range classIDs {
+		pairs[i] = ClassInstance{ClassID: classID}
+	}
+
+	found, err := session.getAssetDescriptions(context.Background(), apiKey, appID, language, pairs)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptions := make(map[uint64]*EconItemDesc, len(found))
+	for _, desc := range found {
+		descriptions[desc.ClassID] = desc
+	}
+
+	return descriptions, nil
+}
+
+// getAssetDescriptions calls fetchAssetClassInfo as many times as the amount of pairs requires.
+func (session *Session) getAssetDescriptions(ctx context.Context, apiKey string, appID uint64, language string, pairs []ClassInstance) (map[string]*EconItemDesc, error) {
+	descriptions := make(map[string]*EconItemDesc, len(pairs))
+	for start := 0; start < len(pairs); start += maxAssetClassInfoCount {
+		end := start + maxAssetClassInfoCount
+		if end > len(pairs) {
+			end = len(pairs)
+		}
+
+		page, err := session.fetchAssetClassInfo(ctx, apiKey, appID, language, pairs[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for key, desc := range page {
+			descriptions[key] = desc
+		}
+	}
+
+	return descriptions, nil
+}
+
+// economyImageURL returns the URL of icon at size as described on EconomyImageURL,
+// icon may already be a full URL.
+func economyImageURL(icon, size string) string {
+	if icon == "" {
+		return ""
+	}
+
+	if !strings.HasPrefix(icon, "http://") && !strings.HasPrefix(icon, "https://") {
+		icon = EconomyImageURL + icon
+	}
+
+	if size == "" {
+		return icon
+	}
+
+	return strings.TrimSuffix(icon, "/") + "/" + size
+}
+
+// FetchIcon downloads an icon, iconURL as returned by the IconURLFull methods.
+func (session *Session) FetchIcon(ctx context.Context, iconURL string) ([]byte, error) {
+	resp, err := session.doRequest(ctx, http.MethodGet, iconURL, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return io.ReadAll(resp.Body)
+}
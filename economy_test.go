@@ -0,0 +1,100 @@
+package steam
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// inventoryWithMissingDescription holds two assets, the description of the second one is missing.
+const inventoryWithMissingDescription = `{
+	"success": 1,
+	"total_inventory_count": 2,
+	"assets": [
+		{"appid": 730, "contextid": "2", "assetid": "1", "classid": "10", "instanceid": "0", "amount": "1"},
+		{"appid": 730, "contextid": "2", "assetid": "2", "classid": "20", "instanceid": "0", "amount": "1"}
+	],
+	"descriptions": [
+		{"classid": "10", "instanceid": "0", "marketable": 1, "market_hash_name": "Described"}
+	]
+}`
+
+func newInventoryStubSession(t *testing.T, classInfo string) *Session {
+	t.Helper()
+
+	return newStubSession(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/inventory/"):
+			io.WriteString(w, inventoryWithMissingDescription)
+		case strings.HasPrefix(r.URL.Path, "/ISteamEconomy/GetAssetClassInfo/"):
+			if r.URL.Query().Get("classid0") != "20" {
+				t.Errorf("GetAssetClassInfo asked for class %q, want 20", r.URL.Query().Get("classid0"))
+			}
+			io.WriteString(w, classInfo)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestInventoryMissingDescriptions(t *testing.T) {
+	session := newInventoryStubSession(t, "")
+
+	items, err := session.GetInventoryWithOptions(SteamID(76561197960287930), 730, 2, &InventoryOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 || items[0].Desc == nil || items[1].Desc != nil {
+		t.Fatalf("got %+v, want the second item without description", items)
+	}
+
+	/* Filters must cope with the nil description.  */
+	items, err = session.GetInventoryWithOptions(SteamID(76561197960287930), 730, 2, &InventoryOptions{
+		Filters: []Filter{FilterMarketable(), FilterMarketableNow()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 || items[0].AssetID != 1 {
+		t.Errorf("filtered items = %+v, want only asset 1", items)
+	}
+
+	items, err = session.GetInventoryWithOptions(SteamID(76561197960287930), 730, 2, &InventoryOptions{SkipMissingDescriptions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 || items[0].AssetID != 1 {
+		t.Errorf("SkipMissingDescriptions kept %+v, want only asset 1", items)
+	}
+}
+
+func TestInventoryFillMissingDescriptions(t *testing.T) {
+	session := newInventoryStubSession(t, `{"result": {"success": true, "20": {"classid": "20", "instanceid": "0", "marketable": "1", "market_hash_name": "Filled"}}}`)
+	session.SetAPIKey("key")
+
+	items, err := session.GetInventoryWithOptions(SteamID(76561197960287930), 730, 2, &InventoryOptions{FillMissingDescriptions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 || items[1].Desc == nil || items[1].Desc.MarketHashName != "Filled" {
+		t.Fatalf("got %+v, want the second description filled in", items)
+	}
+}
+
+func TestAssetClassInfoError(t *testing.T) {
+	for _, result := range []string{`{"error": "invalid class"}`, `{"error": 42}`} {
+		session := newInventoryStubSession(t, `{"result": `+result+`}`)
+		session.SetAPIKey("key")
+
+		_, err := session.GetInventoryWithOptions(SteamID(76561197960287930), 730, 2, &InventoryOptions{FillMissingDescriptions: true})
+		if !errors.Is(err, ErrAssetClassInfo) {
+			t.Errorf("%s: got %v, want ErrAssetClassInfo", result, err)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package steam
+
+import (
+	"math"
+)
+
+const (
+	steamFeePercent = 0.05
+	steamFeeMinimum = 1
+)
+
+type marketFees struct {
+	steamFee     int64
+	publisherFee int64
+	amount       int64 // What the buyer pays
+}
+
+// amountToSend mirrors Steam's CalculateAmountToSendForDesiredReceivedAmount.
+func amountToSend(receive int64, publisherFeePercent float64) marketFees {
+	steamFee := int64(math.Floor(math.Max(float64(receive)*steamFeePercent, steamFeeMinimum)))
+
+	publisherFee := int64(0)
+	if publisherFeePercent > 0 {
+		publisherFee = int64(math.Floor(math.Max(float64(receive)*publisherFeePercent, 1)))
+	}
+
+	return marketFees{steamFee, publisherFee, receive + steamFee + publisherFee}
+}
+
+// feeAmount mirrors Steam's CalculateFeeAmount, it finds the fees included in amount.
+func feeAmount(amount int64, publisherFeePercent float64) marketFees {
+	estimate := int64(float64(amount) / (steamFeePercent + publisherFeePercent + 1))
+	undershot := false
+
+	fees := amountToSend(estimate, publisherFeePercent)
+	for i := 0; fees.amount != amount && i < 10; i++ {
+		if fees.amount > amount {
+			if undershot {
+				/* No received amount leads to amount, Steam takes the difference.  */
+				fees = amountToSend(estimate-1, publisherFeePercent)
+				fees.steamFee += amount - fees.amount
+				fees.amount = amount
+				break
+			}
+
+			estimate--
+		} else {
+			undershot = true
+			estimate++
+		}
+
+		fees = amountToSend(estimate, publisherFeePercent)
+	}
+
+	return fees
+}
+
+// CalculateSellerReceives returns what the seller receives when the buyer pays priceCents.
+// publisherFeePercent is the game's fee, e.g. 0.1 for most games.
+func CalculateSellerReceives(priceCents int64, publisherFeePercent float64) int64 {
+	fees := feeAmount(priceCents, publisherFeePercent)
+	return fees.amount - fees.steamFee - fees.publisherFee
+}
+
+// CalculateBuyerPays returns what the buyer pays for the seller to receive receiveCents,
+// which is the price SellItem expects.
+func CalculateBuyerPays(receiveCents int64, publisherFeePercent float64) int64 {
+	return amountToSend(receiveCents, publisherFeePercent).amount
+}
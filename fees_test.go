@@ -0,0 +1,46 @@
+package steam
+
+import "testing"
+
+func TestCalculateSellerReceives(t *testing.T) {
+	tests := []struct {
+		name         string
+		price        int64
+		publisherFee float64
+		want         int64
+	}{
+		{"minimum price, 1 cent fees", 3, 0.1, 1},
+		{"one dollar", 100, 0.1, 88},
+		{"ten dollars", 1000, 0.1, 870},
+		{"no exact received amount", 22, 0.1, 19},
+		{"zero publisher fee, minimum price", 2, 0, 1},
+		{"zero publisher fee", 100, 0, 96},
+	}
+
+	for _, tt := range tests {
+		if got := CalculateSellerReceives(tt.price, tt.publisherFee); got != tt.want {
+			t.Errorf("%s: CalculateSellerReceives(%d, %v) = %d, want %d", tt.name, tt.price, tt.publisherFee, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateBuyerPays(t *testing.T) {
+	tests := []struct {
+		name         string
+		receive      int64
+		publisherFee float64
+		want         int64
+	}{
+		{"minimum price, 1 cent fees", 1, 0.1, 3},
+		{"one dollar", 88, 0.1, 100},
+		{"ten dollars", 870, 0.1, 1000},
+		{"zero publisher fee, minimum price", 1, 0, 2},
+		{"zero publisher fee", 96, 0, 100},
+	}
+
+	for _, tt := range tests {
+		if got := CalculateBuyerPays(tt.receive, tt.publisherFee); got != tt.want {
+			t.Errorf("%s: CalculateBuyerPays(%d, %v) = %d, want %d", tt.name, tt.receive, tt.publisherFee, got, tt.want)
+		}
+	}
+}
@@ -14,6 +14,10 @@ type Filter func(*InventoryItem) bool
 // IsSouvenir filters souvenir items
 func IsSouvenir(cond bool) Filter {
 	return func(item *InventoryItem) bool {
+		if item.Desc == nil {
+			return !cond
+		}
+
 		for _, tag := range item.Desc.Tags {
 			if tag.Category == "Quality" && tag.InternalName == "tournament" {
 				return cond
@@ -23,3 +27,61 @@ func IsSouvenir(cond bool) Filter {
 		return !cond
 	}
 }
+
+// Filters below that inspect item.Desc reject items without a description.
+
+// FilterTradable filters items that can be traded
+func FilterTradable() Filter {
+	return func(item *InventoryItem) bool {
+		return item.Desc != nil && item.Desc.Tradable != 0
+	}
+}
+
+// FilterMarketable filters items that can be sold on the community market
+func FilterMarketable() Filter {
+	return func(item *InventoryItem) bool {
+		return item.Desc != nil && item.Desc.Marketable != 0
+	}
+}
+
+// FilterMarketableNow filters items that can be sold right away, unlike FilterMarketable
+// it also rejects items under a market hold, see EconItemDesc.MarketableAt
+func FilterMarketableNow() Filter {
+	return func(item *InventoryItem) bool {
+		if item.Desc == nil {
+			return false
+		}
+
+		at, ok := item.Desc.MarketableAt()
+		return ok && at.IsZero()
+	}
+}
+
+// FilterByAppID filters items that belong to appID
+func FilterByAppID(appID uint32) Filter {
+	return func(item *InventoryItem) bool {
+		return item.AppID == appID
+	}
+}
+
+// FilterByMarketHashName filters items with the given market hash name
+func FilterByMarketHashName(name string) Filter {
+	return func(item *InventoryItem) bool {
+		return item.Desc != nil && item.Desc.MarketHashName == name
+	}
+}
+
+// FilterByClassID filters items of the given class
+func FilterByClassID(classID uint64) Filter {
+	return func(item *InventoryItem) bool {
+		return item.ClassID == classID
+	}
+}
+
+// FilterMinAmount filters stacks of at least n items, Amount is the size of
+// the stack of each asset (e.g. Gems), it is 1 for items that do not stack.
+func FilterMinAmount(n uint64) Filter {
+	return func(item *InventoryItem) bool {
+		return item.Amount >= n
+	}
+}
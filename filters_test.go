@@ -0,0 +1,70 @@
+package steam
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMarketableAt(t *testing.T) {
+	future := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+	past := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name    string
+		json    string
+		ok      bool
+		at      time.Time
+		days    int // Hold expected from now when at is unknown
+		sellNow bool
+	}{
+		{"no restriction", `{"marketable":1}`, true, time.Time{}, 0, true},
+		{
+			"held with cache_expiration",
+			fmt.Sprintf(`{"marketable":1,"market_tradable_restriction":7,"market_marketable_restriction":7,"cache_expiration":%q}`, future.Format(time.RFC3339)),
+			true, future, 0, false,
+		},
+		{"held without cache_expiration", `{"marketable":1,"market_marketable_restriction":3}`, true, time.Time{}, 3, false},
+		{
+			"hold over",
+			fmt.Sprintf(`{"marketable":1,"market_marketable_restriction":7,"cache_expiration":%q}`, past.Format(time.RFC3339)),
+			true, time.Time{}, 0, true,
+		},
+		{
+			"never marketable",
+			fmt.Sprintf(`{"marketable":0,"cache_expiration":%q}`, future.Format(time.RFC3339)),
+			false, time.Time{}, 0, false,
+		},
+	}
+
+	for _, tt := range tests {
+		var desc EconItemDesc
+		if err := json.Unmarshal([]byte(tt.json), &desc); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		at, ok := desc.MarketableAt()
+		if ok != tt.ok {
+			t.Errorf("%s: ok = %v, want %v", tt.name, ok, tt.ok)
+		}
+
+		switch {
+		case tt.days != 0:
+			want := time.Now().AddDate(0, 0, tt.days)
+			if d := want.Sub(at); d < 0 || d > time.Minute {
+				t.Errorf("%s: at = %v, want about %v", tt.name, at, want)
+			}
+		case !at.Equal(tt.at):
+			t.Errorf("%s: at = %v, want %v", tt.name, at, tt.at)
+		}
+
+		if got := FilterMarketableNow()(&InventoryItem{Desc: &desc}); got != tt.sellNow {
+			t.Errorf("%s: FilterMarketableNow = %v, want %v", tt.name, got, tt.sellNow)
+		}
+	}
+
+	if FilterMarketableNow()(&InventoryItem{}) {
+		t.Error("FilterMarketableNow accepted an item without description")
+	}
+}
@@ -1,6 +1,8 @@
 package steam
 
 import (
+	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
@@ -12,18 +14,39 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
 	InventoryEndpoint           = "http://steamcommunity.com/inventory/%d/%d/%d?"
 	contextInventoryEndpoint    = "profiles/%s/inventory/"
+	legacyInventoryEndpoint     = SteamcommunityURL + "profiles/%s/inventory/json/%d/%d/?"
 	steamTimeAPI                = "https://api.steampowered.com/ITwoFactorService/QueryTime/v0001"
 	getConfirmationListEndpoint = SteamcommunityURL + "mobileconf/getlist?p=%s&a=%s&k=%s&t=%s&m=%s&tag=%s"
 	acceptConfirmation          = SteamcommunityURL + "mobileconf/ajaxop?op=%s&p=%s&a=%s&k=%s&t=%s&m=react&tag=%s&cid=%s&ck=%s"
+	multiAcceptConfirmation     = SteamcommunityURL + "mobileconf/multiajaxop"
+	confirmationDetails         = SteamcommunityURL + "mobileconf/detailspage/%s?p=%s&a=%s&k=%s&t=%s&m=react&tag=%s"
 	conf                        = "conf"
 )
 
+const (
+	firstInventoryPageSize = 250
+	inventoryPageSize      = 75
+	maxInventoryPageSize   = 2000
+
+	defaultBaseBackoff  = time.Second
+	defaultSteamTimeTTL = time.Hour
+
+	defaultInventoryConcurrency = 4
+
+	confirmationPollInterval    = time.Second
+	maxConfirmationPollInterval = 30 * time.Second
+)
+
 type ItemTag struct {
 	Category              string `json:"category"`
 	InternalName          string `json:"internal_name"`
@@ -39,8 +62,8 @@ type InventoryItem struct {
 	AssetID    uint64        `json:"id,string,omitempty"`
 	ClassID    uint64        `json:"classid,string,omitempty"`
 	InstanceID uint64        `json:"instanceid,string,omitempty"`
-	Amount     uint64        `json:"amount,string"`
-	Desc       *EconItemDesc `json:"-"` /* May be nil  */
+	Amount     uint64        `json:"amount,string"`         /* Stack size of this asset  */
+	Desc       *EconItemDesc `json:"description,omitempty"` /* May be nil  */
 }
 
 type InventoryContext struct {
@@ -49,6 +72,8 @@ type InventoryContext struct {
 	Name       string `json:"name"`
 }
 
+// InventoryAppStats describes an app present in a profile's inventory,
+// as found in the g_rgAppContextData variable of the inventory page.
 type InventoryAppStats struct {
 	AppID            uint64                       `json:"appid"`
 	Name             string                       `json:"name"`
@@ -57,35 +82,106 @@ type InventoryAppStats struct {
 	Link             string                       `json:"link"`
 	InventoryLogo    string                       `json:"inventory_logo"`
 	TradePermissions string                       `json:"trade_permissions"`
+	LoadFailed       uint64                       `json:"load_failed"`
+	StoreVetted      string                       `json:"store_vetted"`
+	OwnerOnly        bool                         `json:"owner_only"`
 	Contexts         map[string]*InventoryContext `json:"rgContexts"`
 }
 
-var inventoryContextRegexp = regexp.MustCompile("var g_rgAppContextData = (.*?);")
+// InventoryOptions tunes how an inventory is loaded.
+type InventoryOptions struct {
+	// PageSize is the number of assets requested per page.
+	// Zero keeps the defaults (250 for the first page, 75 for the next ones),
+	// anything above 2000 is clamped since Steam refuses bigger pages.
+	// Some apps cap pages at 1000 and will answer with fewer assets.
+	PageSize uint64
+
+	// StartAssetID makes loading start after the given asset instead of the beginning.
+	StartAssetID uint64
+
+	Filters []Filter
+
+	// SkipMissingDescriptions drops assets whose description was not sent by Steam,
+	// otherwise such items are returned with a nil Desc.
+	SkipMissingDescriptions bool
+
+	// Language overrides the session language for descriptions of this call only.
+	Language string
+
+	// FillMissingDescriptions looks up descriptions missing from the inventory
+	// response with ISteamEconomy/GetAssetClassInfo, which needs the session's Web API key.
+	FillMissingDescriptions bool
+
+	// LegacyFallback loads the inventory from the legacy inventory/json endpoint
+	// when the first page cannot be loaded, some apps and friends-only inventories
+	// are still served there.  StartAssetID and PageSize do not apply to it.
+	LegacyFallback bool
+}
+
+func (opts *InventoryOptions) pageSize(startAssetID uint64) uint64 {
+	switch {
+	case opts.PageSize == 0 && startAssetID == 0:
+		return firstInventoryPageSize
+	case opts.PageSize == 0:
+		return inventoryPageSize
+	case opts.PageSize > maxInventoryPageSize:
+		return maxInventoryPageSize
+	}
+
+	return opts.PageSize
+}
+
+// inventoryContextRegexp matches the whole g_rgAppContextData assignment line, either as
+// a plain object literal or wrapped as an escaped string in JSON.parse("...").
+var inventoryContextRegexp = regexp.MustCompile(`(?m)g_rgAppContextData\s*=\s*(.+?);\s*$`)
+
+const (
+	privateProfilePattern  = "profile_private_info"
+	profileNotFoundPattern = "The specified profile could not be found."
+)
+
+var (
+	ErrInventoryPrivate         = errors.New("inventory is private")
+	ErrInventoryEmpty           = errors.New("inventory is empty")
+	ErrInventoryAppNotFound     = errors.New("app is not present in inventory")
+	ErrInventoryContextNotFound = errors.New("context is not present in inventory")
+	ErrProfilePrivate           = errors.New("profile is private")
+	ErrProfileNotFound          = errors.New("profile could not be found")
+	ErrCannotFindAppContextData = errors.New("unable to find g_rgAppContextData in inventory page")
+)
 
 func (session *Session) fetchInventory(
+	ctx context.Context,
 	sid SteamID,
 	appID, contextID, startAssetID uint64,
-	filters []Filter,
+	opts *InventoryOptions,
 	items *[]InventoryItem,
-) (hasMore bool, lastAssetID uint64, err error) {
+) (hasMore bool, lastAssetID uint64, totalCount int, err error) {
+	language := session.language
+	if opts.Language != "" {
+		language = opts.Language
+	}
+
 	params := url.Values{
-		"l": {session.language},
+		"l": {language},
 	}
 
 	if startAssetID != 0 {
 		params.Set("start_assetid", strconv.FormatUint(startAssetID, 10))
-		params.Set("count", "75")
-	} else {
-		params.Set("count", "250")
 	}
+	params.Set("count", strconv.FormatUint(opts.pageSize(startAssetID), 10))
 
-	resp, err := session.client.Get(fmt.Sprintf(InventoryEndpoint, sid, appID, contextID) + params.Encode())
-	if resp != nil {
-		defer resp.Body.Close()
+	header := session.inventoryHeaders.Clone()
+	if header == nil {
+		header = http.Header{}
 	}
 
-	if err != nil {
-		return false, 0, err
+	if header.Get("Referer") == "" {
+		header.Set("Referer", fmt.Sprintf(SteamcommunityURL+contextInventoryEndpoint, sid.ToString()))
+	}
+
+	if lang := acceptLanguage(language); lang != "" && header.Get("Accept-Language") == "" {
+		header.Set("Accept-Language", lang)
 	}
 
 	type Asset struct {
@@ -107,38 +203,100 @@ func (session *Session) fetchInventory(
 		ErrorMsg            string          `json:"error"`
 	}
 
+	/* Steam throttles inventories with a response lacking any data rather than
+	   with HTTP 429, these are retried as configured by SetRetryPolicy too.  */
 	var response Response
-	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return false, 0, err
-	}
+	endpoint := fmt.Sprintf(InventoryEndpoint, sid, appID, contextID) + params.Encode()
+	err = retryWithBackoff(ctx, session.retryPolicy, func() error {
+		body, err := session.fetchInventoryBody(ctx, endpoint, header)
+		if err != nil {
+			return err
+		}
+
+		response = Response{}
+		if err = json.Unmarshal(body, &response); err != nil {
+			return err
+		}
 
-	if response.Success == 0 {
-		if len(response.ErrorMsg) != 0 {
-			return false, 0, errors.New(response.ErrorMsg)
+		if response.Success != 0 {
+			return nil
 		}
 
-		return false, 0, nil // empty inventory
+		msg := strings.ToLower(response.ErrorMsg)
+		switch {
+		case strings.Contains(msg, "private"):
+			return ErrInventoryPrivate
+		case strings.Contains(msg, "too many"):
+			session.reportRateLimit(endpoint)
+			return &retryableError{fmt.Errorf("%w: %s", ErrRateLimited, response.ErrorMsg), 0}
+		case msg != "":
+			return errors.New(response.ErrorMsg)
+		}
+
+		/* Empty inventories come with success set, Steam only leaves it out when throttling.  */
+		session.reportRateLimit(endpoint)
+		return &retryableError{ErrRateLimited, 0}
+	})
+	if err != nil {
+		return false, 0, 0, err
+	}
+
+	if len(response.Assets) == 0 && response.TotalInventoryCount == 0 {
+		return false, 0, 0, ErrInventoryEmpty
 	}
 
 	// Fill in descriptions map, where key
 	// is "<CLASS_ID>_<INSTANCE_ID>" pattern, and
-	// value is position on asset description in
+	// value is asset description from
 	// response.Descriptions array
 	//
 	// We need it for fast asset's description
 	// searching in future
-	descriptions := make(map[string]int)
-	for i, desc := range response.Descriptions {
+	descriptions := make(map[string]*EconItemDesc)
+	for _, desc := range response.Descriptions {
 		key := fmt.Sprintf("%d_%d", desc.ClassID, desc.InstanceID)
-		descriptions[key] = i
+		if cached, ok := session.cachedDescription(appID, language, key); ok {
+			desc = cached
+		} else {
+			session.cacheDescription(appID, language, key, desc)
+		}
+		descriptions[key] = desc
 	}
 
-	for _, asset := range response.Assets {
-		var desc *EconItemDesc
+	if opts.FillMissingDescriptions {
+		missing := []ClassInstance{}
+		for _, asset := range response.Assets {
+			key := fmt.Sprintf("%d_%d", asset.ClassID, asset.InstanceID)
+			if _, ok := descriptions[key]; ok {
+				continue
+			}
 
+			if cached, ok := session.cachedDescription(appID, language, key); ok {
+				descriptions[key] = cached
+				continue
+			}
+
+			missing = append(missing, ClassInstance{asset.ClassID, asset.InstanceID})
+		}
+
+		if len(missing) != 0 {
+			found, err := session.getAssetDescriptions(ctx, session.apiKey, appID, language, missing)
+			if err != nil {
+				return false, 0, 0, err
+			}
+
+			for key, desc := range found {
+				session.cacheDescription(appID, language, key, desc)
+				descriptions[key] = desc
+			}
+		}
+	}
+
+	for _, asset := range response.Assets {
 		key := fmt.Sprintf("%d_%d", asset.ClassID, asset.InstanceID)
-		if d, ok := descriptions[key]; ok {
-			desc = response.Descriptions[d]
+		desc, ok := descriptions[key]
+		if !ok && opts.SkipMissingDescriptions {
+			continue
 		}
 
 		item := InventoryItem{
@@ -152,7 +310,7 @@ func (session *Session) fetchInventory(
 		}
 
 		add := true
-		for _, filter := range filters {
+		for _, filter := range opts.Filters {
 			add = filter(&item)
 			if !add {
 				break
@@ -166,45 +324,567 @@ func (session *Session) fetchInventory(
 
 	hasMore = response.HasMore != 0
 	if !hasMore {
-		return hasMore, 0, nil
+		return hasMore, 0, response.TotalInventoryCount, nil
 	}
 
 	lastAssetID, err = strconv.ParseUint(response.LastAssetID, 10, 64)
 	if err != nil {
-		return hasMore, 0, err
+		return hasMore, 0, 0, err
+	}
+
+	return hasMore, lastAssetID, response.TotalInventoryCount, nil
+}
+
+// fetchLegacyInventory loads the whole inventory from the legacy inventory/json endpoint,
+// which keys assets and descriptions by ID instead of listing them.
+func (session *Session) fetchLegacyInventory(ctx context.Context, sid SteamID, appID, contextID uint64, opts *InventoryOptions) ([]InventoryItem, error) {
+	language := session.language
+	if opts.Language != "" {
+		language = opts.Language
+	}
+
+	type Asset struct {
+		AssetID    uint64 `json:"id,string"`
+		ClassID    uint64 `json:"classid,string"`
+		InstanceID uint64 `json:"instanceid,string"`
+		Amount     uint64 `json:"amount,string"`
+		Pos        int    `json:"pos"`
+	}
+
+	type Response struct {
+		Success      bool            `json:"success"`
+		Inventory    json.RawMessage `json:"rgInventory"`
+		Descriptions json.RawMessage `json:"rgDescriptions"`
+		More         bool            `json:"more"`
+		MoreStart    json.RawMessage `json:"more_start"`
+		ErrorMsg     string          `json:"Error"`
+	}
+
+	items := []InventoryItem{}
+	seen := 0
+	start := "0"
+	for {
+		params := url.Values{
+			"l":     {language},
+			"start": {start},
+		}
+
+		var response Response
+		endpoint := fmt.Sprintf(legacyInventoryEndpoint, sid.ToString(), appID, contextID) + params.Encode()
+		if err := session.getJSON(ctx, http.MethodGet, endpoint, nil, "", &response); err != nil {
+			return nil, err
+		}
+
+		if !response.Success {
+			if strings.Contains(strings.ToLower(response.ErrorMsg), "private") {
+				return nil, ErrInventoryPrivate
+			}
+
+			if response.ErrorMsg != "" {
+				return nil, errors.New(response.ErrorMsg)
+			}
+
+			return nil, ErrInventoryEmpty
+		}
+
+		/* Both are sent as an empty array rather than an object when there is nothing.  */
+		assets := map[string]Asset{}
+		if len(response.Inventory) != 0 && response.Inventory[0] == '{' {
+			if err := json.Unmarshal(response.Inventory, &assets); err != nil {
+				return nil, err
+			}
+		}
+
+		descriptions := map[string]*EconItemDesc{}
+		if len(response.Descriptions) != 0 && response.Descriptions[0] == '{' {
+			if err := json.Unmarshal(response.Descriptions, &descriptions); err != nil {
+				return nil, err
+			}
+		}
+
+		seen += len(assets)
+		sorted := make([]Asset, 0, len(assets))
+		for _, asset := range assets {
+			sorted = append(sorted, asset)
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].Pos < sorted[j].Pos })
+
+		for _, asset := range sorted {
+			desc, ok := descriptions[fmt.Sprintf("%d_%d", asset.ClassID, asset.InstanceID)]
+			if !ok && opts.SkipMissingDescriptions {
+				continue
+			}
+
+			item := InventoryItem{
+				AppID:      uint32(appID),
+				ContextID:  contextID,
+				AssetID:    asset.AssetID,
+				ClassID:    asset.ClassID,
+				InstanceID: asset.InstanceID,
+				Amount:     asset.Amount,
+				Desc:       desc,
+			}
+
+			add := true
+			for _, filter := range opts.Filters {
+				add = filter(&item)
+				if !add {
+					break
+				}
+			}
+
+			if add {
+				items = append(items, item)
+			}
+		}
+
+		/* more_start is false on the last page.  */
+		if !response.More || len(response.MoreStart) == 0 || response.MoreStart[0] == 'f' {
+			break
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		start = string(response.MoreStart)
+	}
+
+	if seen == 0 {
+		return nil, ErrInventoryEmpty
+	}
+
+	return items, nil
+}
+
+func (session *Session) cachedDescription(appID uint64, language, key string) (*EconItemDesc, bool) {
+	if session.descriptionCache == nil {
+		return nil, false
+	}
+
+	return session.descriptionCache.Get(descriptionCacheKey(appID, language, key))
+}
+
+func (session *Session) cacheDescription(appID uint64, language, key string, desc *EconItemDesc) {
+	if session.descriptionCache != nil {
+		session.descriptionCache.Set(descriptionCacheKey(appID, language, key), desc)
+	}
+}
+
+func descriptionCacheKey(appID uint64, language, key string) string {
+	return strconv.FormatUint(appID, 10) + "/" + language + "/" + key
+}
+
+// getWithRetry performs a GET request with the given headers, retrying it when Steam answers
+// with HTTP 429 as configured by SetRetryPolicy.
+func (session *Session) getWithRetry(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	var resp *http.Response
+	err := retryWithBackoff(ctx, session.retryPolicy, func() (err error) {
+		resp, err = session.getOnce(ctx, url, header)
+		return err
+	})
+
+	return resp, err
+}
+
+// getOnce is a single attempt of getWithRetry, HTTP 429 is returned as a retryable error.
+func (session *Session) getOnce(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := session.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		return nil, session.rateLimited(url, resp)
+	}
+
+	if err = decompressBody(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// fetchInventoryBody requests an inventory page once and returns its body.
+func (session *Session) fetchInventoryBody(ctx context.Context, endpoint string, header http.Header) ([]byte, error) {
+	resp, err := session.getOnce(ctx, endpoint, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, ErrInventoryPrivate
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
-	return hasMore, lastAssetID, nil
+	/* Private inventories are answered with a bare null.  */
+	if bytes.Equal(bytes.TrimSpace(body), []byte("null")) {
+		return nil, ErrInventoryPrivate
+	}
+
+	return body, nil
 }
 
+// GetInventory loads the whole inventory of sid for the given app and context.
+// Inventories that cannot be read or hold no item are reported as ErrInventoryPrivate
+// and ErrInventoryEmpty, which all the inventory loading methods share.
 func (session *Session) GetInventory(sid SteamID, appID, contextID uint64) ([]InventoryItem, error) {
+	return session.GetInventoryContextAware(context.Background(), sid, appID, contextID)
+}
+
+// GetInventoryContextAware is like GetInventory, but every request is bound to ctx.
+func (session *Session) GetInventoryContextAware(ctx context.Context, sid SteamID, appID, contextID uint64) ([]InventoryItem, error) {
 	filters := []Filter{}
 
-	return session.GetFilterableInventory(sid, appID, contextID, filters)
+	return session.GetFilterableInventoryContextAware(ctx, sid, appID, contextID, filters)
 }
 
 func (session *Session) GetFilterableInventory(sid SteamID, appID, contextID uint64, filters []Filter) ([]InventoryItem, error) {
+	return session.GetFilterableInventoryContextAware(context.Background(), sid, appID, contextID, filters)
+}
+
+// GetFilterableInventoryContextAware is like GetFilterableInventory, but every request is bound to ctx.
+// If ctx is done between two pages, the items loaded so far are returned together with ctx.Err().
+func (session *Session) GetFilterableInventoryContextAware(ctx context.Context, sid SteamID, appID, contextID uint64, filters []Filter) ([]InventoryItem, error) {
+	return session.GetInventoryWithOptionsContextAware(ctx, sid, appID, contextID, &InventoryOptions{Filters: filters})
+}
+
+// GetInventoryWithOptions loads the whole inventory (starting at opts.StartAssetID) using opts.
+func (session *Session) GetInventoryWithOptions(sid SteamID, appID, contextID uint64, opts *InventoryOptions) ([]InventoryItem, error) {
+	return session.GetInventoryWithOptionsContextAware(context.Background(), sid, appID, contextID, opts)
+}
+
+// GetInventoryWithOptionsContextAware is like GetInventoryWithOptions, but every request is bound to ctx.
+// If ctx is done between two pages, the items loaded so far are returned together with ctx.Err().
+func (session *Session) GetInventoryWithOptionsContextAware(ctx context.Context, sid SteamID, appID, contextID uint64, opts *InventoryOptions) ([]InventoryItem, error) {
+	items, _, err := session.loadInventory(ctx, sid, appID, contextID, opts)
+	return items, err
+}
+
+// GetTradableInventory returns the tradable items sid owns in appID.
+// The context is resolved from the inventory page: when the app has several
+// contexts, the one holding the most assets is used (the lowest ID on a tie).
+func (session *Session) GetTradableInventory(sid SteamID, appID uint64) ([]InventoryItem, error) {
+	stats, err := session.GetInventoryAppStats(sid)
+	if err != nil {
+		return nil, err
+	}
+
+	app, ok := stats[strconv.FormatUint(appID, 10)]
+	if !ok {
+		return nil, ErrInventoryAppNotFound
+	}
+
+	ctx := app.DefaultContext()
+	if ctx == nil {
+		return nil, ErrInventoryAppNotFound
+	}
+
+	return session.GetFilterableInventory(sid, appID, ctx.ID, []Filter{FilterTradable()})
+}
+
+// GetInventoryForContextID is like GetInventory, but it first checks on the inventory page
+// that sid's inventory has the given context for appID, so a wrong context ID is reported
+// as ErrInventoryContextNotFound (ErrInventoryAppNotFound for a missing app)
+// rather than as an empty inventory.
+func (session *Session) GetInventoryForContextID(sid SteamID, appID uint64, contextID string) ([]InventoryItem, error) {
+	id, err := strconv.ParseUint(contextID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInventoryContextNotFound, contextID)
+	}
+
+	stats, err := session.GetInventoryAppStats(sid)
+	if err != nil {
+		return nil, err
+	}
+
+	app, ok := stats[strconv.FormatUint(appID, 10)]
+	if !ok {
+		return nil, ErrInventoryAppNotFound
+	}
+
+	if _, ok := app.Contexts[strconv.FormatUint(id, 10)]; !ok {
+		return nil, fmt.Errorf("%w: %d for app %d", ErrInventoryContextNotFound, id, appID)
+	}
+
+	return session.GetInventory(sid, appID, id)
+}
+
+// Key identifies the asset of item across inventory loads, as "<APP_ID>_<CONTEXT_ID>_<ASSET_ID>".
+func (item *InventoryItem) Key() string {
+	return fmt.Sprintf("%d_%d_%d", item.AppID, item.ContextID, item.AssetID)
+}
+
+// DiffInventories compares two loads of an inventory by Key: added holds the items
+// of after missing from before, removed those of before missing from after.
+// Stacks whose Amount changed are in neither.
+func DiffInventories(before, after []InventoryItem) (added, removed []InventoryItem) {
+	beforeKeys := make(map[string]bool, len(before))
+	for i := range before {
+		beforeKeys[before[i].Key()] = true
+	}
+
+	afterKeys := make(map[string]bool, len(after))
+	for i := range after {
+		key := after[i].Key()
+		afterKeys[key] = true
+		if !beforeKeys[key] {
+			added = append(added, after[i])
+		}
+	}
+
+	for i := range before {
+		if !afterKeys[before[i].Key()] {
+			removed = append(removed, before[i])
+		}
+	}
+
+	return added, removed
+}
+
+// MergeCommodityStacks collapses the stacks of each commodity item, e.g. trading cards,
+// into a single item whose Amount is their total, in the place of the first stack.
+// Merged items keep the AssetID of their first stack only, the other asset IDs are lost,
+// so the result cannot be used to list or trade the merged stacks.
+// Items without a description or that are not commodities are left untouched.
+func MergeCommodityStacks(items []InventoryItem) []InventoryItem {
+	merged := make([]InventoryItem, 0, len(items))
+	positions := make(map[ClassInstance]int)
+	for _, item := range items {
+		if item.Desc == nil || !item.Desc.Comodity {
+			merged = append(merged, item)
+			continue
+		}
+
+		key := ClassInstance{item.ClassID, item.InstanceID}
+		if i, ok := positions[key]; ok {
+			merged[i].Amount += item.Amount
+			continue
+		}
+
+		positions[key] = len(merged)
+		merged = append(merged, item)
+	}
+
+	return merged
+}
+
+// DefaultContext returns the context holding the most assets (the lowest ID on a tie),
+// nil if the app has none.
+func (app InventoryAppStats) DefaultContext() *InventoryContext {
+	var primary *InventoryContext
+	for _, ctx := range app.Contexts {
+		if primary == nil ||
+			ctx.AssetCount > primary.AssetCount ||
+			(ctx.AssetCount == primary.AssetCount && ctx.ID < primary.ID) {
+			primary = ctx
+		}
+	}
+
+	return primary
+}
+
+// ContextByName returns the context named name, compared case-insensitively,
+// e.g. "Backpack" or "Gifts".
+func (app InventoryAppStats) ContextByName(name string) (*InventoryContext, bool) {
+	var found *InventoryContext
+	for _, ctx := range app.Contexts {
+		if strings.EqualFold(ctx.Name, name) && (found == nil || ctx.ID < found.ID) {
+			found = ctx
+		}
+	}
+
+	return found, found != nil
+}
+
+// InventoryTarget identifies one inventory of a profile.
+type InventoryTarget struct {
+	AppID     uint64
+	ContextID uint64
+}
+
+// InventoryTargetError is the error of loading one of the targets of GetInventories.
+type InventoryTargetError struct {
+	Target InventoryTarget
+	Err    error
+}
+
+func (e *InventoryTargetError) Error() string {
+	return fmt.Sprintf("inventory %d/%d: %v", e.Target.AppID, e.Target.ContextID, e.Err)
+}
+
+func (e *InventoryTargetError) Unwrap() error {
+	return e.Err
+}
+
+// GetInventories loads several inventories of sid, at most concurrency at a time (4 if not positive).
+// Failed targets are left out of the result and their errors, each wrapped in an
+// InventoryTargetError, are joined in the returned error along with the loaded inventories.
+func (session *Session) GetInventories(sid SteamID, targets []InventoryTarget, concurrency int) (map[InventoryTarget][]InventoryItem, error) {
+	if concurrency <= 0 {
+		concurrency = defaultInventoryConcurrency
+	}
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		errs   []error
+		result = make(map[InventoryTarget][]InventoryItem, len(targets))
+		sem    = make(chan struct{}, concurrency)
+	)
+
+	for _, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(target InventoryTarget) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			items, err := session.GetInventory(sid, target.AppID, target.ContextID)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, &InventoryTargetError{Target: target, Err: err})
+				return
+			}
+
+			result[target] = items
+		}(target)
+	}
+
+	wg.Wait()
+
+	return result, errors.Join(errs...)
+}
+
+// GetInventoryWithLanguage is like GetInventory, but descriptions are in lang
+// instead of the session language.
+func (session *Session) GetInventoryWithLanguage(sid SteamID, appID, contextID uint64, lang string) ([]InventoryItem, error) {
+	return session.GetInventoryWithOptions(sid, appID, contextID, &InventoryOptions{Language: lang})
+}
+
+// GetInventoryWithCount is like GetInventory, but it also returns the total
+// inventory count reported by Steam on the first page.
+func (session *Session) GetInventoryWithCount(sid SteamID, appID, contextID uint64) ([]InventoryItem, int, error) {
+	return session.loadInventory(context.Background(), sid, appID, contextID, nil)
+}
+
+func (session *Session) loadInventory(ctx context.Context, sid SteamID, appID, contextID uint64, opts *InventoryOptions) ([]InventoryItem, int, error) {
+	if opts == nil {
+		opts = &InventoryOptions{}
+	}
+
 	items := []InventoryItem{}
-	startAssetID := uint64(0)
+	startAssetID := opts.StartAssetID
+	totalCount := 0
+
+	for page := 0; ; page++ {
+		hasMore, lastAssetID, total, err := session.fetchInventory(ctx, sid, appID, contextID, startAssetID, opts, &items)
+		/* A throttled request would only be throttled again on the legacy endpoint.  */
+		if err != nil && page == 0 && opts.LegacyFallback && ctx.Err() == nil && !errors.Is(err, ErrRateLimited) {
+			if items, legacyErr := session.fetchLegacyInventory(ctx, sid, appID, contextID, opts); legacyErr == nil {
+				return items, len(items), nil
+			}
+		}
 
-	for {
-		hasMore, lastAssetID, err := session.fetchInventory(sid, appID, contextID, startAssetID, filters, &items)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
+		}
+
+		if page == 0 {
+			totalCount = total
 		}
 
 		if !hasMore {
 			break
 		}
 
+		if err = ctx.Err(); err != nil {
+			return items, totalCount, err
+		}
+
 		startAssetID = lastAssetID
 	}
 
-	return items, nil
+	return items, totalCount, nil
+}
+
+// GetInventoryPage loads a single page of the inventory, starting after startAssetID
+// (0 for the first page).  It returns the page items matching filters, the asset ID to
+// pass as startAssetID for the next page and whether there is one.
+func (session *Session) GetInventoryPage(sid SteamID, appID, contextID, startAssetID uint64, filters []Filter) ([]InventoryItem, uint64, bool, error) {
+	items := []InventoryItem{}
+	hasMore, lastAssetID, _, err := session.fetchInventory(context.Background(), sid, appID, contextID, startAssetID, &InventoryOptions{Filters: filters}, &items)
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	return items, lastAssetID, hasMore, nil
+}
+
+// GetInventoryStream loads the inventory page by page and calls fn for every item
+// as soon as its page arrives, so the whole inventory never has to be kept in memory.
+// Loading stops at the first non-nil error returned by fn, which is then returned.
+func (session *Session) GetInventoryStream(sid SteamID, appID, contextID uint64, fn func(InventoryItem) error) error {
+	ctx := context.Background()
+	opts := &InventoryOptions{}
+	startAssetID := uint64(0)
+	page := []InventoryItem{}
+
+	for {
+		page = page[:0]
+		hasMore, lastAssetID, _, err := session.fetchInventory(ctx, sid, appID, contextID, startAssetID, opts, &page)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range page {
+			if err = fn(item); err != nil {
+				return err
+			}
+		}
+
+		if !hasMore {
+			return nil
+		}
+
+		startAssetID = lastAssetID
+	}
 }
 
 func (session *Session) GetInventoryAppStats(sid SteamID) (map[string]InventoryAppStats, error) {
-	resp, err := session.client.Get("https://steamcommunity.com/profiles/" + sid.ToString() + "/inventory")
+	return session.GetInventoryAppStatsContextAware(context.Background(), sid)
+}
+
+// GetInventoryAppStatsContextAware is like GetInventoryAppStats, but the request is bound to ctx.
+func (session *Session) GetInventoryAppStatsContextAware(ctx context.Context, sid SteamID) (map[string]InventoryAppStats, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(SteamcommunityURL+contextInventoryEndpoint, sid.ToString()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := session.httpClient().Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -213,59 +893,95 @@ func (session *Session) GetInventoryAppStats(sid SteamID) (map[string]InventoryA
 		return nil, err
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
+	return parseAppContextData(body)
+}
+
+// parseAppContextData extracts g_rgAppContextData from an inventory page,
+// the returned map is keyed by app id.
+func parseAppContextData(body []byte) (map[string]InventoryAppStats, error) {
 	m := inventoryContextRegexp.FindSubmatch(body)
 	if m == nil || len(m) != 2 {
-		return nil, err
+		switch {
+		case bytes.Contains(body, []byte(privateProfilePattern)):
+			return nil, ErrProfilePrivate
+		case bytes.Contains(body, []byte(profileNotFoundPattern)):
+			return nil, ErrProfileNotFound
+		}
+
+		return nil, ErrCannotFindAppContextData
+	}
+
+	data := bytes.TrimSpace(m[1])
+	if bytes.HasPrefix(data, []byte("JSON.parse(")) {
+		var unescaped string
+		if err := json.Unmarshal(bytes.TrimSuffix(bytes.TrimPrefix(data, []byte("JSON.parse(")), []byte(")")), &unescaped); err != nil {
+			return nil, err
+		}
+
+		data = []byte(unescaped)
 	}
 
 	inven := map[string]InventoryAppStats{}
-	if err = json.Unmarshal(m[1], &inven); err != nil {
+
+	/* Steam sends an empty array rather than an object when there are no apps.  */
+	if bytes.Equal(data, []byte("[]")) {
+		return inven, nil
+	}
+
+	if err := json.Unmarshal(data, &inven); err != nil {
 		return nil, err
 	}
 
 	return inven, nil
 }
 
+// GetInventoryContext returns the apps and contexts of the inventory of steamID.
+//
+// Deprecated: use GetInventoryAppStats, which parses the same data.
 func (session *Session) GetInventoryContext(steamID string) (*SteamInventoryContext, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(SteamcommunityURL+contextInventoryEndpoint, steamID), nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create a request, err: %v", err)
-	}
-
-	resp, err := session.client.Do(req)
+	id, err := strconv.ParseUint(steamID, 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get html page, err: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	stats, err := session.GetInventoryAppStats(SteamID(id))
 	if err != nil {
-		return nil, fmt.Errorf("cannot read html page, err: %v", err)
-	}
-
-	re := regexp.MustCompile(`g_rgAppContextData\s*=\s*(\{.*?\});`)
-	match := re.FindStringSubmatch(string(body))
-
-	if len(match) == 0 {
-		return nil, fmt.Errorf("inventory context is empty")
+		return nil, err
 	}
 
-	if len(match) < 2 {
-		return nil, fmt.Errorf("cannot get g_rgAppContextData in html page")
-	}
+	invContext := SteamInventoryContext{}
+	for appID, app := range stats {
+		contexts := make(map[string]Context, len(app.Contexts))
+		for id, ctx := range app.Contexts {
+			contexts[id] = Context{
+				AssetCount: uint64(ctx.AssetCount),
+				ID:         strconv.FormatUint(ctx.ID, 10),
+				Name:       ctx.Name,
+			}
+		}
 
-	var invContext SteamInventoryContext
-	if err := json.Unmarshal([]byte(match[1]), &invContext); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal json, err %v", err)
+		invContext[appID] = GameContext{
+			AppID:         app.AppID,
+			Name:          app.Name,
+			Icon:          app.Icon,
+			Link:          app.Link,
+			AssetCount:    uint64(app.AssetCount),
+			InventoryLogo: app.InventoryLogo,
+			TradePerms:    app.TradePermissions,
+			LoadFailed:    app.LoadFailed,
+			StoreVetted:   app.StoreVetted,
+			OwnerOnly:     app.OwnerOnly,
+			RGContexts:    contexts,
+		}
 	}
 
 	return &invContext, nil
@@ -301,6 +1017,10 @@ func generateConfirmationHashForTime(identitySecret string, tag string, timestam
 }
 
 func (s *Session) FetchConfirmations(identitySecret string) (*ConfirmationResponse, error) {
+	return s.fetchConfirmations(context.Background(), identitySecret)
+}
+
+func (s *Session) fetchConfirmations(ctx context.Context, identitySecret string) (*ConfirmationResponse, error) {
 	timestamp, err := s.getSteamTime()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Steam time: %w", err)
@@ -315,108 +1035,321 @@ func (s *Session) FetchConfirmations(identitySecret string) (*ConfirmationRespon
 
 	confListEndpoint := fmt.Sprintf(getConfirmationListEndpoint, s.deviceID, steamID.ToString(), hash, strconv.FormatInt(timestamp, 10), "react", conf)
 
-	req, err := http.NewRequest(http.MethodGet, confListEndpoint, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	if s.confirmationsVersion == ConfirmationsV2 {
+		confirmations, err := s.fetchConfirmationsV2(ctx, confListEndpoint)
+		if err == nil || errors.Is(err, ErrNotLoggedIn) {
+			return confirmations, err
+		}
+	}
+
+	confirmations := ConfirmationResponse{}
+	if err := s.getJSON(ctx, http.MethodGet, confListEndpoint, nil, "", &confirmations); err != nil {
+		return nil, err
+	}
+
+	return &confirmations, nil
+}
+
+// fetchConfirmationsV2 loads the confirmation list as the mobile app does, which
+// gets the failure explained in the response instead of an empty list.
+func (s *Session) fetchConfirmationsV2(ctx context.Context, endpoint string) (*ConfirmationResponse, error) {
+	resp, err := s.getWithRetry(ctx, endpoint, mobileAppHeaders.Clone())
+	if resp != nil {
+		defer resp.Body.Close()
 	}
 
-	resp, err := s.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return nil, &HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return nil, err
 	}
 
-	confirmations := ConfirmationResponse{}
-	if err := json.Unmarshal(body, &confirmations); err != nil {
-		return nil, fmt.Errorf("error parsing response JSON: %w", err)
+	confirmations := &ConfirmationResponse{}
+	if err = json.Unmarshal(body, confirmations); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w (body: %s)", err, bodySnippet(body))
 	}
 
-	return &confirmations, nil
+	if confirmations.NeedAuth {
+		return nil, ErrNotLoggedIn
+	}
+
+	if !confirmations.Success {
+		if confirmations.Message != "" {
+			return nil, fmt.Errorf("%w: %s", ErrCannotFindConfirmations, confirmations.Message)
+		}
+
+		return nil, ErrCannotFindConfirmations
+	}
+
+	return confirmations, nil
 }
 
-func (s *Session) getSteamTime() (int64, error) {
-	req, err := http.NewRequest(http.MethodPost, steamTimeAPI, nil)
+// SyncSteamTime queries Steam's server time and stores its offset to the local
+// clock, confirmations then derive timestamps locally until the offset gets stale.
+func (s *Session) SyncSteamTime() error {
+	serverTime, err := s.querySteamTime()
 	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %v", err)
+		return err
 	}
 
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	now := time.Now()
 
-	resp, err := s.client.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("failed to send request: %v", err)
+	s.timeMu.Lock()
+	s.timeOffset = time.Unix(serverTime, 0).Sub(now)
+	s.timeSyncedAt = now
+	s.timeMu.Unlock()
+
+	return nil
+}
+
+func (s *Session) getSteamTime() (int64, error) {
+	s.timeMu.Lock()
+	ttl := s.timeSyncTTL
+	if ttl <= 0 {
+		ttl = defaultSteamTimeTTL
 	}
-	defer resp.Body.Close()
+	stale := s.timeSyncedAt.IsZero() || time.Since(s.timeSyncedAt) > ttl
+	s.timeMu.Unlock()
 
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("Steam API returned status code %d", resp.StatusCode)
+	if stale {
+		if err := s.SyncSteamTime(); err != nil {
+			return 0, err
+		}
 	}
 
+	s.timeMu.Lock()
+	defer s.timeMu.Unlock()
+
+	return time.Now().Add(s.timeOffset).Unix(), nil
+}
+
+func (s *Session) querySteamTime() (int64, error) {
 	var result SteamTimeResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, fmt.Errorf("failed to parse Steam time response: %v", err)
+	if err := s.getJSON(context.Background(), http.MethodPost, steamTimeAPI, url.Values{}, "", &result); err != nil {
+		return 0, fmt.Errorf("failed to query Steam time: %w", err)
 	}
 
 	return result.SteamTime.ServerTime, nil
 }
 
-func (s *Session) AcceptConfirmation(identitySecret string) (*ConfirmationResponse, error) {
+// AcceptConfirmation accepts every pending confirmation, the results are keyed by confirmation ID.
+// It stops at the first failure and returns the results gathered so far along with the error.
+func (s *Session) AcceptConfirmation(identitySecret string) (map[string]*ConfirmationAcceptResponse, error) {
+	confirmations, err := s.FetchConfirmations(identitySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]*ConfirmationAcceptResponse, len(confirmations.Confirmations))
+	for _, conf := range confirmations.Confirmations {
+		result, err := s.SendConfirmationAjax(conf, ConfirmationAccept, identitySecret)
+		if err != nil {
+			return results, fmt.Errorf("failed to accept confirmation %s: %w", conf.ID, err)
+		}
+
+		results[conf.ID] = result
+	}
+
+	return results, nil
+}
+
+// AcceptConfirmationByID accepts the pending confirmation with the given ID.
+func (s *Session) AcceptConfirmationByID(id string, identitySecret string) (*ConfirmationAcceptResponse, error) {
+	confirmations, err := s.FetchConfirmations(identitySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, conf := range confirmations.Confirmations {
+		if conf.ID == id {
+			return s.SendConfirmationAjax(conf, ConfirmationAccept, identitySecret)
+		}
+	}
+
+	return nil, ErrCannotFindConfirmations
+}
+
+// AcceptAllMarketConfirmations accepts the pending market listing confirmations, and only them,
+// in a single request. It returns the accepted confirmations.
+func (s *Session) AcceptAllMarketConfirmations(identitySecret string) ([]*Confirmation, error) {
+	return s.acceptConfirmationsOfType(identitySecret, ConfirmationTypeMarketListing)
+}
+
+// AcceptAllTradeConfirmations is like AcceptAllMarketConfirmations for trade offer confirmations.
+func (s *Session) AcceptAllTradeConfirmations(identitySecret string) ([]*Confirmation, error) {
+	return s.acceptConfirmationsOfType(identitySecret, ConfirmationTypeTrade)
+}
+
+func (s *Session) acceptConfirmationsOfType(identitySecret string, confType uint8) ([]*Confirmation, error) {
+	confirmations, err := s.FetchConfirmations(identitySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := []*Confirmation{}
+	for _, conf := range confirmations.Confirmations {
+		if conf.Type == confType {
+			matching = append(matching, conf)
+		}
+	}
+
+	if len(matching) == 0 {
+		return matching, nil
+	}
+
+	result, err := s.SendMultiConfirmationAjax(matching, ConfirmationAccept, identitySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Success {
+		return nil, fmt.Errorf("cannot accept %d confirmations", len(matching))
+	}
+
+	return matching, nil
+}
+
+// WaitForConfirmation polls the pending confirmations until match returns true for
+// one of them, waiting longer between each attempt, or until ctx is done.
+// Attempts failing with network errors, HTTP 429 or 5xx are retried the same way,
+// any other error is returned at once.  It only gives up on a confirmation that never
+// shows up once ctx is done, so ctx must carry a deadline.
+func (s *Session) WaitForConfirmation(ctx context.Context, identitySecret string, match func(*Confirmation) bool) (*Confirmation, error) {
+	var lastErr error
+
+	interval := confirmationPollInterval
+	for {
+		confirmations, err := s.fetchConfirmations(ctx, identitySecret)
+		switch {
+		case err != nil && !isTransient(err):
+			return nil, err
+		case err != nil:
+			lastErr = err
+		default:
+			lastErr = nil
+			for _, conf := range confirmations.Confirmations {
+				if match(conf) {
+					return conf, nil
+				}
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			if lastErr != nil {
+				return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+			}
+
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		if interval *= 2; interval > maxConfirmationPollInterval {
+			interval = maxConfirmationPollInterval
+		}
+	}
+}
+
+var (
+	confTradeOfferRegexp = regexp.MustCompile(`tradeofferid_(\d+)`)
+	confListingRegexp    = regexp.MustCompile(`"listingid"\s*:\s*"(\d+)"|mylisting_(\d+)`)
+)
+
+// ConfirmationDetails tells which action a confirmation belongs to,
+// only one of the IDs is set depending on the confirmation kind.
+type ConfirmationDetails struct {
+	TradeOfferID uint64
+	ListingID    uint64
+}
+
+// GetConfirmationDetails returns the trade offer or market listing conf was created for,
+// as told by conf.Creator or, for other kinds of confirmations, by their details page.
+func (s *Session) GetConfirmationDetails(conf *Confirmation, identitySecret string) (*ConfirmationDetails, error) {
+	if id, err := strconv.ParseUint(conf.Creator, 10, 64); err == nil && id != 0 {
+		switch conf.Type {
+		case ConfirmationTypeTrade:
+			return &ConfirmationDetails{TradeOfferID: id}, nil
+		case ConfirmationTypeMarketListing:
+			return &ConfirmationDetails{ListingID: id}, nil
+		}
+	}
+
+	tag := "details" + conf.ID
+
 	timestamp, err := s.getSteamTime()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Steam time: %w", err)
 	}
 
-	hash, err := generateConfirmationHashForTime(identitySecret, conf, timestamp)
+	hash, err := generateConfirmationHashForTime(identitySecret, tag, timestamp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate confirmation hash: %w", err)
 	}
 
 	steamID := s.GetSteamID()
 
-	confListEndpoint := fmt.Sprintf(getConfirmationListEndpoint, s.deviceID, steamID.ToString(), hash, strconv.FormatInt(timestamp, 10), "react", conf)
+	detailsEndpoint := fmt.Sprintf(confirmationDetails, conf.ID, s.deviceID, steamID.ToString(), hash, strconv.FormatInt(timestamp, 10), tag)
 
-	req, err := http.NewRequest(http.MethodGet, confListEndpoint, nil)
+	resp, err := s.doRequest(context.Background(), http.MethodGet, detailsEndpoint, nil, "")
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	resp, err := s.client.Do(req)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, fmt.Errorf("error reading response: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	/* Older clients get {"success":true,"html":"..."}, newer ones the page itself.  */
+	var wrapped struct {
+		Success bool   `json:"success"`
+		HTML    string `json:"html"`
 	}
+	if json.Unmarshal(body, &wrapped) == nil {
+		if !wrapped.Success {
+			return nil, ErrConfirmationDetailsNotFound
+		}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		body = []byte(wrapped.HTML)
 	}
 
-	confirmations := ConfirmationResponse{}
-	if err := json.Unmarshal(body, &confirmations); err != nil {
-		return nil, fmt.Errorf("error parsing response JSON: %w", err)
+	details := &ConfirmationDetails{}
+	if m := confTradeOfferRegexp.FindSubmatch(body); m != nil {
+		if details.TradeOfferID, err = strconv.ParseUint(string(m[1]), 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid trade offer ID: %w", err)
+		}
+	} else if m := confListingRegexp.FindSubmatch(body); m != nil {
+		id := m[1]
+		if len(id) == 0 {
+			id = m[2]
+		}
+
+		if details.ListingID, err = strconv.ParseUint(string(id), 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid listing ID: %w", err)
+		}
+	} else {
+		return nil, ErrConfirmationDetailsNotFound
 	}
 
-	return &confirmations, nil
+	return details, nil
 }
 
+// SendConfirmationAjax answers conf, tag is ConfirmationAccept or ConfirmationReject,
+// any other tag is refused with ErrInvalidConfirmationTag.
 func (s *Session) SendConfirmationAjax(conf *Confirmation, tag, is string) (*ConfirmationAcceptResponse, error) {
-	//tag can be only reject or accept
-	op := "cancel"
-	if tag == "accept" {
-		op = "allow"
+	op, err := confirmationOp(tag)
+	if err != nil {
+		return nil, err
 	}
 
 	timestamp, err := s.getSteamTime()
@@ -433,25 +1366,55 @@ func (s *Session) SendConfirmationAjax(conf *Confirmation, tag, is string) (*Con
 
 	confListEndpoint := fmt.Sprintf(acceptConfirmation, op, s.deviceID, steamID.ToString(), hash, strconv.FormatInt(timestamp, 10), tag, conf.ID, conf.Nonce)
 
-	req, err := http.NewRequest(http.MethodGet, confListEndpoint, nil)
-	if err != nil {
+	confAccessResponse := &ConfirmationAcceptResponse{}
+	if err := s.getJSON(context.Background(), http.MethodGet, confListEndpoint, nil, "", confAccessResponse); err != nil {
 		return nil, err
 	}
 
-	resp, err := s.client.Do(req)
+	return confAccessResponse, nil
+}
+
+// SendMultiConfirmationAjax accepts or rejects all confs in a single request.
+func (s *Session) SendMultiConfirmationAjax(confs []*Confirmation, tag, is string) (*ConfirmationAcceptResponse, error) {
+	op, err := confirmationOp(tag)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	timestamp, err := s.getSteamTime()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Steam time: %w", err)
+	}
+
+	hash, err := generateConfirmationHashForTime(is, tag, timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation hash: %w", err)
+	}
+
+	/* The hash is already query-escaped, it has to be sent raw in the form body.  */
+	key, err := url.QueryUnescape(hash)
 	if err != nil {
 		return nil, err
 	}
 
+	steamID := s.GetSteamID()
+
+	params := url.Values{
+		"op":  {op},
+		"p":   {s.deviceID},
+		"a":   {steamID.ToString()},
+		"k":   {key},
+		"t":   {strconv.FormatInt(timestamp, 10)},
+		"m":   {"react"},
+		"tag": {tag},
+	}
+	for _, conf := range confs {
+		params.Add("cid[]", conf.ID)
+		params.Add("ck[]", conf.Nonce)
+	}
+
 	confAccessResponse := &ConfirmationAcceptResponse{}
-	err = json.Unmarshal(body, confAccessResponse)
-	if err != nil {
+	if err := s.getJSON(context.Background(), http.MethodPost, multiAcceptConfirmation, params, "", confAccessResponse); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,53 @@
+package steam
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInventoryThrottleRetried(t *testing.T) {
+	var calls int32
+	session := newStubSession(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			io.WriteString(w, `{"success": 0}`)
+			return
+		}
+
+		io.WriteString(w, inventoryWithMissingDescription)
+	}))
+	session.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	items, err := session.GetInventory(SteamID(76561197960287930), 730, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 2 || calls != 3 {
+		t.Errorf("got %d items after %d requests, want 2 items after 3 requests", len(items), calls)
+	}
+}
+
+func TestInventoryThrottleSkipsLegacyFallback(t *testing.T) {
+	var legacyCalls int32
+	session := newStubSession(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/inventory/json/") {
+			atomic.AddInt32(&legacyCalls, 1)
+		}
+
+		io.WriteString(w, `{"success": 0, "error": "Too many requests"}`)
+	}))
+
+	_, err := session.GetInventoryWithOptions(SteamID(76561197960287930), 730, 2, &InventoryOptions{LegacyFallback: true})
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("got %v, want ErrRateLimited", err)
+	}
+
+	if legacyCalls != 0 {
+		t.Errorf("the legacy endpoint was requested %d times on a throttled inventory", legacyCalls)
+	}
+}
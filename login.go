@@ -17,6 +17,8 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/ilayzen/steam/pb"
@@ -48,6 +50,7 @@ type OAuth struct {
 
 type Session struct {
 	client      *http.Client
+	clientOnce  sync.Once
 	oauth       OAuth
 	sessionID   string
 	apiKey      string
@@ -55,6 +58,39 @@ type Session struct {
 	umqID       string
 	chatMessage int
 	language    string
+	retryPolicy RetryPolicy
+	onRateLimit RateLimitFunc
+
+	confirmationsVersion int
+
+	timeMu       sync.Mutex
+	timeOffset   time.Duration
+	timeSyncedAt time.Time
+	timeSyncTTL  time.Duration
+
+	itemNameIDsMu sync.Mutex
+	itemNameIDs   map[string]uint64
+
+	priceOverviewsMu sync.Mutex
+	priceOverviewTTL time.Duration
+	priceOverviews   map[priceOverviewKey]priceOverviewEntry
+
+	transportMu   sync.Mutex
+	limiters      map[string]RateLimiter
+	hook          RequestHook
+	proxyProvider ProxyProvider
+	debugMu       sync.Mutex
+	debugWriter   io.Writer
+
+	profileMu  sync.Mutex
+	profileURL string
+
+	walletMu         sync.Mutex
+	walletCurrencyID string
+	walletCountry    string
+
+	inventoryHeaders http.Header
+	descriptionCache DescriptionCache
 }
 
 const (
@@ -328,7 +364,9 @@ func (session *Session) finalizeLogin(pollAuth *pb.CAuthentication_PollAuthSessi
 		break
 	}
 
-	session.client.Jar = jar
+	session.httpClient().Jar = jar
+	session.resetProfileURL()
+	session.resetWalletLocale()
 
 	return nil
 }
@@ -366,11 +404,13 @@ func (session *Session) Login(accountName, password, sharedSecret string, timeOf
 		return err
 	}
 
-	sum := md5.Sum([]byte(accountName + password))
-	session.deviceID = fmt.Sprintf(
-		"android:%x-%x-%x-%x-%x",
-		sum[:2], sum[2:4], sum[4:6], sum[6:8], sum[8:10],
-	)
+	if session.deviceID == "" {
+		sum := md5.Sum([]byte(accountName + password))
+		session.deviceID = fmt.Sprintf(
+			"android:%x-%x-%x-%x-%x",
+			sum[:2], sum[2:4], sum[4:6], sum[6:8], sum[8:10],
+		)
+	}
 
 	session.oauth.SteamID = SteamID(*authSession.Steamid)
 	session.addMobileAuthCookies()
@@ -380,12 +420,12 @@ func (session *Session) Login(accountName, password, sharedSecret string, timeOf
 
 func (session *Session) Refresh() error {
 
-	resp, err := session.client.Get(RefreshSession)
+	resp, err := session.httpClient().Get(RefreshSession)
 	if err != nil {
 		return err
 	}
 
-	jar := session.client.Jar
+	jar := session.httpClient().Jar
 	for _, cookie := range resp.Cookies() {
 		if cookie.Name == "steamRefresh_steam" {
 			jar.SetCookies(&url.URL{Scheme: "https", Host: "login.steampowered.com"}, []*http.Cookie{cookie})
@@ -396,7 +436,7 @@ func (session *Session) Refresh() error {
 	return nil
 }
 
-func (session Session) addMobileAuthCookies() {
+func (session *Session) addMobileAuthCookies() {
 
 	cookies := []*http.Cookie{
 		{Name: "mobileClientVersion", Value: "0 (2.1.3)"},
@@ -406,7 +446,18 @@ func (session Session) addMobileAuthCookies() {
 		{Name: "dob", Value: ""},
 	}
 
-	session.client.Jar.SetCookies(&url.URL{Scheme: "https", Host: "steamcommunity.com"}, cookies)
+	session.httpClient().Jar.SetCookies(&url.URL{Scheme: "https", Host: "steamcommunity.com"}, cookies)
+}
+
+// IsLoggedIn reports whether the session's cookies are still valid, Steam redirects
+// the profile shortcut to the login page once they have expired.
+func (session *Session) IsLoggedIn() (bool, error) {
+	profileURL, err := session.RefreshProfileURL()
+	if err != nil {
+		return false, err
+	}
+
+	return !strings.Contains(profileURL, "/login"), nil
 }
 
 func (session *Session) GetSteamID() SteamID {
@@ -417,18 +468,49 @@ func (session *Session) SetLanguage(lang string) {
 	session.language = lang
 }
 
+// SetInventoryHeaders sets headers sent along with every inventory request.
+// Steam answers requests for other users' inventories with empty responses when they
+// do not look like they come from a browser, Accept-Language and User-Agent matter most.
+// The Referer defaults to the inventory page of the target profile.
+func (session *Session) SetInventoryHeaders(header http.Header) {
+	session.inventoryHeaders = header.Clone()
+}
+
+// SetDescriptionCache makes inventory loads reuse descriptions from cache, e.g. NewLRUDescriptionCache(10000).
+// A nil cache disables caching, which is the default.
+func (session *Session) SetDescriptionCache(cache DescriptionCache) {
+	session.descriptionCache = cache
+}
+
+// SetSteamTimeTTL sets how long the offset to Steam's server time is trusted
+// before confirmations query it again, it defaults to one hour.
+func (session *Session) SetSteamTimeTTL(ttl time.Duration) {
+	session.timeMu.Lock()
+	session.timeSyncTTL = ttl
+	session.timeMu.Unlock()
+}
+
+// SetRetryBackoff makes rate-limited (HTTP 429) requests be retried up to
+// maxRetries times, waiting baseBackoff doubled on every attempt unless Steam
+// sends a Retry-After header.  Retries are disabled by default, see SetRetryPolicy.
+func (session *Session) SetRetryBackoff(maxRetries int, baseBackoff time.Duration) {
+	session.SetRetryPolicy(RetryPolicy{
+		MaxAttempts: maxRetries + 1,
+		BaseDelay:   baseBackoff,
+	})
+}
+
 func NewSessionWithAPIKey(apiKey string) *Session {
 	return &Session{
-		client:   &http.Client{},
+		client:   newDefaultClient(),
 		apiKey:   apiKey,
 		language: "english",
 	}
 }
 
-func NewSession(client *http.Client, apiKey string) *Session {
-	return &Session{
-		client:   client,
-		apiKey:   apiKey,
-		language: "english",
-	}
+// NewSession creates a session sending its requests with client (the default one when nil)
+// and using apiKey, further configured by opts.  It keeps its original parameters so that
+// existing callers still build, NewSessionWithOptions takes nothing but options.
+func NewSession(client *http.Client, apiKey string, opts ...SessionOption) *Session {
+	return NewSessionWithOptions(append([]SessionOption{WithHTTPClient(client), WithAPIKey(apiKey)}, opts...)...)
 }
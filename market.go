@@ -1,6 +1,8 @@
 package steam
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,8 +11,11 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode"
 )
 
 const (
@@ -18,8 +23,19 @@ const (
 )
 
 const (
-	marketEndpoint         = "%smarket/search/render/?norender=1&appid=%d&start=%d&count=%d"
-	myListingItemsEndpoint = "%s/market/mylistings?start=%d&count=%d&norender=1"
+	marketEndpoint          = SteamcommunityURL + "market/search/render/?"
+	myListingItemsEndpoint  = "%s/market/mylistings?start=%d&count=%d&norender=1"
+	marketListingEndpoint   = "%smarket/listings/%d/%s"
+	ordersHistogramEndpoint = SteamcommunityURL + "market/itemordershistogram?"
+
+	maxMarketSearchPageSize = 100
+
+	sellConfirmationAttempts = 10
+	sellConfirmationInterval = 3 * time.Second
+
+	// priceOverviewInterval spaces the requests of GetPriceOverviews when the session
+	// has no rate limiter for steamcommunity.com, Steam allows about 20 a minute.
+	priceOverviewInterval = 3 * time.Second
 )
 
 const (
@@ -109,24 +125,443 @@ var WalletMap = map[string]string{
 	"RMB":  "23", // RMB
 }
 
+// CurrencyCodes maps currency IDs to their ISO 4217 code.
+var CurrencyCodes = map[string]string{
+	CurrencyUSD: "USD",
+	CurrencyGBP: "GBP",
+	CurrencyEUR: "EUR",
+	CurrencyCHF: "CHF",
+	CurrencyRUB: "RUB",
+	CurrencyPLN: "PLN",
+	CurrencyBRL: "BRL",
+	CurrencyJPY: "JPY",
+	CurrencyNOK: "NOK",
+	CurrencyIDR: "IDR",
+	CurrencyMYR: "MYR",
+	CurrencyPHP: "PHP",
+	CurrencySGD: "SGD",
+	CurrencyTHB: "THB",
+	CurrencyVND: "VND",
+	CurrencyKRW: "KRW",
+	CurrencyTRY: "TRY",
+	CurrencyUAH: "UAH",
+	CurrencyMXN: "MXN",
+	CurrencyCAD: "CAD",
+	CurrencyAUD: "AUD",
+	CurrencyNZD: "NZD",
+	CurrencyCNY: "CNY",
+	CurrencyINR: "INR",
+	CurrencyCLP: "CLP",
+	CurrencyPEN: "PEN",
+	CurrencyCOP: "COP",
+	CurrencyZAR: "ZAR",
+	CurrencyHKD: "HKD",
+	CurrencyTWD: "TWD",
+	CurrencySAR: "SAR",
+	CurrencyAED: "AED",
+	CurrencyARS: "ARS",
+	CurrencyILS: "ILS",
+	CurrencyBYN: "BYN",
+	CurrencyKZT: "KZT",
+	CurrencyKWD: "KWD",
+	CurrencyQAR: "QAR",
+	CurrencyCRC: "CRC",
+	CurrencyUYU: "UYU",
+	CurrencyRMB: "RMB",
+}
+
+var currencyCountries = map[string]string{
+	CurrencyUSD: "US",
+	CurrencyGBP: "GB",
+	CurrencyEUR: "DE",
+	CurrencyCHF: "CH",
+	CurrencyRUB: "RU",
+	CurrencyPLN: "PL",
+	CurrencyBRL: "BR",
+	CurrencyJPY: "JP",
+	CurrencyNOK: "NO",
+	CurrencyIDR: "ID",
+	CurrencyMYR: "MY",
+	CurrencyPHP: "PH",
+	CurrencySGD: "SG",
+	CurrencyTHB: "TH",
+	CurrencyVND: "VN",
+	CurrencyKRW: "KR",
+	CurrencyTRY: "TR",
+	CurrencyUAH: "UA",
+	CurrencyMXN: "MX",
+	CurrencyCAD: "CA",
+	CurrencyAUD: "AU",
+	CurrencyNZD: "NZ",
+	CurrencyCNY: "CN",
+	CurrencyINR: "IN",
+	CurrencyCLP: "CL",
+	CurrencyPEN: "PE",
+	CurrencyCOP: "CO",
+	CurrencyZAR: "ZA",
+	CurrencyHKD: "HK",
+	CurrencyTWD: "TW",
+	CurrencySAR: "SA",
+	CurrencyAED: "AE",
+	CurrencyARS: "AR",
+	CurrencyILS: "IL",
+	CurrencyBYN: "BY",
+	CurrencyKZT: "KZ",
+	CurrencyKWD: "KW",
+	CurrencyQAR: "QA",
+	CurrencyCRC: "CR",
+	CurrencyUYU: "UY",
+	CurrencyRMB: "CN",
+}
+
+type currencyFormat struct {
+	symbol     string
+	suffix     bool // The symbol follows the amount
+	space      bool // The symbol and the amount are separated by a space
+	decimal    string
+	group      string
+	noDecimals bool
+}
+
+// currencyFormats describes how Steam displays amounts of each currency.
+var currencyFormats = map[string]currencyFormat{
+	CurrencyUSD: {symbol: "$", decimal: ".", group: ","},
+	CurrencyGBP: {symbol: "£", decimal: ".", group: ","},
+	CurrencyEUR: {symbol: "€", suffix: true, decimal: ",", group: " "},
+	CurrencyCHF: {symbol: "CHF", space: true, decimal: ".", group: " "},
+	CurrencyRUB: {symbol: "pуб.", suffix: true, space: true, decimal: ",", group: " "},
+	CurrencyPLN: {symbol: "zł", suffix: true, decimal: ",", group: " "},
+	CurrencyBRL: {symbol: "R$", space: true, decimal: ",", group: "."},
+	CurrencyJPY: {symbol: "¥", space: true, decimal: ".", group: ",", noDecimals: true},
+	CurrencyNOK: {symbol: "kr", suffix: true, space: true, decimal: ",", group: " "},
+	CurrencyIDR: {symbol: "Rp", space: true, decimal: ".", group: " ", noDecimals: true},
+	CurrencyMYR: {symbol: "RM", decimal: ".", group: ","},
+	CurrencyPHP: {symbol: "₱", decimal: ".", group: ","},
+	CurrencySGD: {symbol: "S$", decimal: ".", group: ","},
+	CurrencyTHB: {symbol: "฿", decimal: ".", group: ","},
+	CurrencyVND: {symbol: "₫", suffix: true, decimal: ",", group: ".", noDecimals: true},
+	CurrencyKRW: {symbol: "₩", space: true, decimal: ".", group: ",", noDecimals: true},
+	CurrencyTRY: {symbol: "TL", suffix: true, space: true, decimal: ",", group: "."},
+	CurrencyUAH: {symbol: "₴", suffix: true, decimal: ",", group: " "},
+	CurrencyMXN: {symbol: "Mex$", space: true, decimal: ".", group: ","},
+	CurrencyCAD: {symbol: "CDN$", space: true, decimal: ".", group: ","},
+	CurrencyAUD: {symbol: "A$", space: true, decimal: ".", group: ","},
+	CurrencyNZD: {symbol: "NZ$", space: true, decimal: ".", group: ","},
+	CurrencyCNY: {symbol: "¥", space: true, decimal: ".", group: ","},
+	CurrencyINR: {symbol: "₹", space: true, decimal: ".", group: ","},
+	CurrencyCLP: {symbol: "CLP$", space: true, decimal: ",", group: ".", noDecimals: true},
+	CurrencyPEN: {symbol: "S/.", decimal: ".", group: ","},
+	CurrencyCOP: {symbol: "COL$", space: true, decimal: ",", group: ".", noDecimals: true},
+	CurrencyZAR: {symbol: "R", space: true, decimal: ".", group: " "},
+	CurrencyHKD: {symbol: "HK$", space: true, decimal: ".", group: ","},
+	CurrencyTWD: {symbol: "NT$", space: true, decimal: ".", group: ",", noDecimals: true},
+	CurrencySAR: {symbol: "SR", suffix: true, space: true, decimal: ".", group: ","},
+	CurrencyAED: {symbol: "AED", suffix: true, space: true, decimal: ".", group: ","},
+	CurrencyARS: {symbol: "ARS$", space: true, decimal: ",", group: "."},
+	CurrencyILS: {symbol: "₪", decimal: ".", group: ","},
+	CurrencyBYN: {symbol: "Br", space: true, decimal: ".", group: " "},
+	CurrencyKZT: {symbol: "₸", suffix: true, decimal: ",", group: " ", noDecimals: true},
+	CurrencyKWD: {symbol: "KD", suffix: true, space: true, decimal: ".", group: ","},
+	CurrencyQAR: {symbol: "QR", suffix: true, space: true, decimal: ".", group: ","},
+	CurrencyCRC: {symbol: "₡", decimal: ",", group: ".", noDecimals: true},
+	CurrencyUYU: {symbol: "$U", decimal: ",", group: ".", noDecimals: true},
+	CurrencyRMB: {symbol: "¥", space: true, decimal: ".", group: ","},
+}
+
+// CountryForCurrency returns a country code Steam accepts along with currencyID,
+// or an empty string if the currency is unknown.
+func CountryForCurrency(currencyID string) string {
+	return currencyCountries[currencyID]
+}
+
+type WalletInfo struct {
+	Balance      string // As displayed, e.g. "$12.34 USD"
+	BalanceCents int64
+	CurrencyCode string
+	CurrencyID   string
+}
+
 type MarketItemPriceOverview struct {
 	Success     bool   `json:"success"`
 	LowestPrice string `json:"lowest_price"`
 	MedianPrice string `json:"median_price"`
 	Volume      string `json:"volume"`
+
+	// Set by ParsePrices, left to zero when the price is missing.
+	LowestPriceCents int64 `json:"-"`
+	MedianPriceCents int64 `json:"-"`
+
+	// ParseError is why the prices could not be converted into cents
+	// by GetMarketItemPriceOverview, nil if they were.
+	ParseError error `json:"-"`
+}
+
+// ParsePrices converts the localized LowestPrice and MedianPrice
+// strings (e.g. "$1,234.56" or "1.234,56€") into integer cents.
+func (overview *MarketItemPriceOverview) ParsePrices() error {
+	return overview.ParsePricesForCurrency("")
+}
+
+// ParsePricesForCurrency is like ParsePrices for prices in currencyID, see ParsePriceCentsForCurrency.
+func (overview *MarketItemPriceOverview) ParsePricesForCurrency(currencyID string) error {
+	var err error
+	if overview.LowestPriceCents, err = ParsePriceCentsForCurrency(overview.LowestPrice, currencyID); err != nil {
+		return err
+	}
+
+	overview.MedianPriceCents, err = ParsePriceCentsForCurrency(overview.MedianPrice, currencyID)
+	return err
+}
+
+// ParsePriceCentsForCurrency converts a price of currencyID as displayed by Steam into cents,
+// its symbol prefix or suffix and decimal separator are those Steam uses for the currency.
+// Prices of an unknown currency, or lacking its symbol, are parsed by ParsePriceCents.
+func ParsePriceCentsForCurrency(price, currencyID string) (int64, error) {
+	format, ok := currencyFormats[currencyID]
+	if !ok {
+		return ParsePriceCents(price)
+	}
+
+	amount := strings.TrimSpace(price)
+	if format.suffix {
+		amount = strings.TrimSuffix(amount, format.symbol)
+	} else {
+		amount = strings.TrimPrefix(amount, format.symbol)
+	}
+
+	if amount == strings.TrimSpace(price) {
+		return ParsePriceCents(price)
+	}
+
+	/* Drop the group separators and what Steam appends, e.g. "1,--€" or "$1.23 USD".  */
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) || string(r) == format.decimal {
+			return r
+		}
+		return -1
+	}, amount)
+
+	whole, fraction, _ := strings.Cut(cleaned, format.decimal)
+	if whole == "" && fraction == "" {
+		return 0, nil
+	}
+
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price %q: %v", price, err)
+	}
+
+	if format.noDecimals || fraction == "" {
+		return units * 100, nil
+	}
+
+	if len(fraction) == 1 {
+		fraction += "0"
+	}
+
+	cents, err := strconv.ParseInt(fraction, 10, 64)
+	if err != nil || len(fraction) > 2 {
+		return 0, fmt.Errorf("invalid price %q", price)
+	}
+
+	return units*100 + cents, nil
+}
+
+// ParsePriceCents converts a price as displayed by Steam into cents.
+// Both comma and dot are accepted as decimal separator, a separator followed
+// by exactly three digits is taken as a thousands separator.  An empty price is 0.
+func ParsePriceCents(price string) (int64, error) {
+	cleaned, _, _ := cleanPrice(price)
+	cleaned = strings.Trim(cleaned, ",.")
+	if cleaned == "" {
+		return 0, nil
+	}
+
+	whole, fraction := cleaned, ""
+	if i := strings.LastIndexAny(cleaned, ",."); i != -1 && len(cleaned)-i-1 <= 2 {
+		whole, fraction = cleaned[:i], cleaned[i+1:]
+	}
+
+	whole = strings.NewReplacer(",", "", ".", "").Replace(whole)
+	if whole == "" {
+		whole = "0"
+	}
+
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid price %q: %v", price, err)
+	}
+
+	cents := int64(0)
+	if fraction != "" {
+		if len(fraction) == 1 {
+			fraction += "0"
+		}
+
+		if cents, err = strconv.ParseInt(fraction, 10, 64); err != nil {
+			return 0, fmt.Errorf("invalid price %q: %v", price, err)
+		}
+	}
+
+	return units*100 + cents, nil
+}
+
+// MarketOrderGraphPoint is a cumulative step of the order book:
+// Quantity orders exist at Price or better.
+type MarketOrderGraphPoint struct {
+	Price       float64
+	Quantity    uint64
+	Description string
+}
+
+// UnmarshalJSON decodes the [price, quantity, description] triples Steam sends.
+func (point *MarketOrderGraphPoint) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if len(raw) != 3 {
+		return fmt.Errorf("invalid order graph point: %s", data)
+	}
+
+	if err := json.Unmarshal(raw[0], &point.Price); err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(raw[1], &point.Quantity); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw[2], &point.Description)
+}
+
+type MarketItemOrdersHistogram struct {
+	Success         int                     `json:"success"`
+	HighestBuyOrder string                  `json:"highest_buy_order"` // In cents
+	LowestSellOrder string                  `json:"lowest_sell_order"` // In cents
+	BuyOrderGraph   []MarketOrderGraphPoint `json:"buy_order_graph"`
+	SellOrderGraph  []MarketOrderGraphPoint `json:"sell_order_graph"`
+	PricePrefix     string                  `json:"price_prefix"`
+	PriceSuffix     string                  `json:"price_suffix"`
 }
 
 type MarketItemPrice struct {
 	Date  string
 	Price float64
 	Count string
+
+	// Parsed from Date and Count, left to zero when they cannot be parsed.
+	ParsedDate time.Time
+	CountInt   int
+}
+
+// UnmarshalJSON decodes a [date, price, volume] triple of the price history,
+// the volume may come as a string or a number.
+func (item *MarketItemPrice) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	if len(raw) != 3 {
+		return fmt.Errorf("invalid price history point: %s", data)
+	}
+
+	if err := json.Unmarshal(raw[0], &item.Date); err != nil {
+		return fmt.Errorf("invalid price history date: %w", err)
+	}
+
+	if err := json.Unmarshal(raw[1], &item.Price); err != nil {
+		return fmt.Errorf("invalid price history price: %w", err)
+	}
+
+	item.Count = strings.Trim(string(raw[2]), `"`)
+	if _, err := strconv.Atoi(item.Count); err != nil {
+		return fmt.Errorf("invalid price history volume: %s", raw[2])
+	}
+
+	item.ParsedDate, _ = ParsePriceHistoryDate(item.Date)
+	item.CountInt, _ = strconv.Atoi(item.Count)
+	return nil
+}
+
+// PriceHistoryBucket aggregates the price history points of one interval.
+type PriceHistoryBucket struct {
+	Start  time.Time
+	Open   float64
+	High   float64
+	Low    float64
+	Close  float64
+	Volume uint64
+}
+
+type BuyOrderStatus struct {
+	Success           int    `json:"success"`
+	Active            int    `json:"active"`    // 1 until the order is filled or cancelled
+	Purchased         uint64 `json:"purchased"` // Items bought so far
+	Quantity          uint64 `json:"quantity,string"`
+	QuantityRemaining uint64 `json:"quantity_remaining,string"`
+}
+
+// Sort columns and directions of the market search.
+const (
+	MarketSortPopular  = "popular"
+	MarketSortPrice    = "price"
+	MarketSortQuantity = "quantity"
+	MarketSortName     = "name"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+var marketSortColumns = map[string]bool{
+	MarketSortPopular:  true,
+	MarketSortPrice:    true,
+	MarketSortQuantity: true,
+	MarketSortName:     true,
+}
+
+// MarketSearchOptions narrows and orders the results of SearchMarketItems.
+type MarketSearchOptions struct {
+	Query              string
+	SearchDescriptions bool
+	Filters            map[string]string // Tag parameters, see GetMarketItemsWithQuery
+	SortColumn         string            // One of the MarketSort* constants
+	SortDir            string            // SortAsc (default) or SortDesc
+}
+
+// MarketItemPriceHistory is the price history of an item, Currency is the ID of the
+// currency of the prices (e.g. CurrencyEUR) or empty when it cannot be recognized.
+type MarketItemPriceHistory struct {
+	Currency    string
+	PricePrefix string
+	PriceSuffix string
+	Points      []*MarketItemPrice
 }
 
 type MarketItemResponse struct {
 	Success     bool        `json:"success"`
 	PricePrefix string      `json:"price_prefix"`
 	PriceSuffix string      `json:"price_suffix"`
-	Prices      interface{} `json:"prices"`
+	Prices      PricePoints `json:"prices"`
+}
+
+// PricePoints is the price history sent by Steam as [date, price, volume] triples.
+type PricePoints []*MarketItemPrice
+
+// UnmarshalJSON decodes the points, Steam sends false instead of an empty list.
+func (points *PricePoints) UnmarshalJSON(data []byte) error {
+	if s := string(bytes.TrimSpace(data)); s == "false" || s == "null" {
+		*points = PricePoints{}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]*MarketItemPrice)(points))
 }
 
 type MarketSellResponse struct {
@@ -135,6 +570,7 @@ type MarketSellResponse struct {
 	MobileConfirmationRequired bool   `json:"needs_mobile_confirmation"`
 	EmailConfirmationRequired  bool   `json:"needs_email_confirmation"`
 	EmailDomain                string `json:"email_domain"`
+	Message                    string `json:"message"` // Set on failure
 }
 
 type MarketBuyOrderResponse struct {
@@ -143,30 +579,102 @@ type MarketBuyOrderResponse struct {
 	OrderID uint64 `json:"buy_orderid,string"`
 }
 
+// MarketListingInfo is a sell listing of the listings page of an item.
+// Price and fees are in cents of CurrencyID, the seller's wallet currency (plus 2000),
+// the Converted* ones in the currency of the session's wallet.
+type MarketListingInfo struct {
+	ListingID             string `json:"listingid"`
+	Price                 uint64 `json:"price"`
+	Fee                   uint64 `json:"fee"`
+	PublisherFeeApp       uint64 `json:"publisher_fee_app"`
+	PublisherFeePercent   string `json:"publisher_fee_percent"`
+	CurrencyID            uint64 `json:"currencyid"`
+	SteamFee              uint64 `json:"steam_fee"`
+	PublisherFee          uint64 `json:"publisher_fee"`
+	ConvertedPrice        uint64 `json:"converted_price"`
+	ConvertedFee          uint64 `json:"converted_fee"`
+	ConvertedCurrencyID   uint64 `json:"converted_currencyid"`
+	ConvertedSteamFee     uint64 `json:"converted_steam_fee"`
+	ConvertedPublisherFee uint64 `json:"converted_publisher_fee"`
+	Asset                 struct {
+		Currency  uint64 `json:"currency"`
+		AppID     uint64 `json:"appid"`
+		ContextID string `json:"contextid"`
+		ID        string `json:"id"`
+		Amount    string `json:"amount"`
+	} `json:"asset"`
+}
+
+// ListingPage holds the data embedded in the listings page of an item.
+type ListingPage struct {
+	ItemNameID uint64
+	Listings   []*MarketListingInfo                   // Cheapest first
+	Assets     map[string]map[string]map[string]Asset // App ID, context ID, asset ID
+}
+
+// FullAsset returns the asset sold by listing.
+func (page *ListingPage) FullAsset(listing *MarketListingInfo) (Asset, bool) {
+	asset, ok := page.Assets[strconv.FormatUint(listing.Asset.AppID, 10)][listing.Asset.ContextID][listing.Asset.ID]
+	return asset, ok
+}
+
+// ItemListings is a page of the public sell listings of an item.
+type ItemListings struct {
+	Start      uint64
+	TotalCount uint64
+	Listings   []*MarketListingInfo                   // Cheapest first
+	Assets     map[string]map[string]map[string]Asset // App ID, context ID, asset ID
+}
+
+// FullAsset returns the asset sold by listing.
+func (listings *ItemListings) FullAsset(listing *MarketListingInfo) (Asset, bool) {
+	asset, ok := listings.Assets[strconv.FormatUint(listing.Asset.AppID, 10)][listing.Asset.ContextID][listing.Asset.ID]
+	return asset, ok
+}
+
 var (
-	ErrCannotLoadPrices = errors.New("unable to load prices at this time")
+	itemNameIDRegexp    = regexp.MustCompile(`Market_LoadOrderSpread\(\s*(\d+)\s*\)`)
+	listingInfoRegexp   = regexp.MustCompile(`(?m)g_rgListingInfo\s*=\s*(.+?);\s*$`)
+	listingAssetsRegexp = regexp.MustCompile(`(?m)g_rgAssets\s*=\s*(.+?);\s*$`)
+	walletInfoRegexp    = regexp.MustCompile(`g_rgWalletInfo\s*=\s*(\{.*?\});`)
+	marketUnlockRegexp  = regexp.MustCompile(`(?:until|on|after) ([A-Z][a-z]+ \d{1,2}(?:, \d{4})?)`)
+	priceNumericRegexp  = regexp.MustCompile(`[^\d,.]`)
+	priceCurrencyRegexp = regexp.MustCompile(`[^\p{L}\p{Sc}]`)
+)
+
+var (
+	ErrCannotLoadPrices     = errors.New("unable to load prices at this time")
+	ErrCannotFindItemNameID = errors.New("unable to find item_nameid in listing page")
+	ErrUnknownCurrency      = errors.New("unknown currency")
+	ErrBuyOrderFailed       = errors.New("cannot place buy order")
+	ErrCannotLoadListings   = errors.New("unable to load listings at this time")
+	ErrCannotLoadBuyOrder   = errors.New("unable to load buy order status")
+	ErrInvalidSortColumn    = errors.New("invalid market search sort")
+	ErrNoLowestPrice        = errors.New("item has no sell listing")
+	ErrMarketNotEligible    = errors.New("account is not allowed to use the market")
+	ErrCannotSellItem       = errors.New("cannot sell item")
 	//ErrInvalidPriceResponse = errors.New("invalid market pricehistory response")
 )
 
+// GetMarketItemPriceHistory returns the price history of an item, see GetMarketItemPriceHistoryWithCurrency.
 func (session *Session) GetMarketItemPriceHistory(appID uint64, marketHashName string) ([]*MarketItemPrice, error) {
-	resp, err := session.client.Get("https://steamcommunity.com/market/pricehistory/?" + url.Values{
-		"appid":            {strconv.FormatUint(appID, 10)},
-		"market_hash_name": {marketHashName},
-	}.Encode())
-	if resp != nil {
-		defer resp.Body.Close()
-	}
-
+	history, err := session.GetMarketItemPriceHistoryWithCurrency(appID, marketHashName)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
-	}
+	return history.Points, nil
+}
 
+// GetMarketItemPriceHistoryWithCurrency returns the price history of an item along with its currency.
+// Prices are in the wallet currency of the logged in account, which is detected from
+// the price prefix and suffix Steam sends along.
+func (session *Session) GetMarketItemPriceHistoryWithCurrency(appID uint64, marketHashName string) (*MarketItemPriceHistory, error) {
 	response := MarketItemResponse{}
-	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := session.getJSON(context.Background(), http.MethodGet, "https://steamcommunity.com/market/pricehistory/?"+url.Values{
+		"appid":            {strconv.FormatUint(appID, 10)},
+		"market_hash_name": {marketHashName},
+	}.Encode(), nil, "", &response); err != nil {
 		return nil, err
 	}
 
@@ -174,314 +682,1257 @@ func (session *Session) GetMarketItemPriceHistory(appID uint64, marketHashName s
 		return nil, ErrCannotLoadPrices
 	}
 
-	var prices []interface{}
-	var ok bool
-	if prices, ok = response.Prices.([]interface{}); !ok {
-		return nil, ErrCannotLoadPrices
+	history := &MarketItemPriceHistory{
+		PricePrefix: response.PricePrefix,
+		PriceSuffix: response.PriceSuffix,
+		Points:      response.Prices,
 	}
-
-	items := []*MarketItemPrice{}
-	for _, v := range prices {
-		if v, ok := v.([]interface{}); ok {
-			item := &MarketItemPrice{}
-			for _, val := range v {
-				switch val := val.(type) {
-				case string:
-					if len(item.Date) != 0 {
-						item.Count = val
-					} else {
-						item.Date = val
-					}
-				case float64:
-					item.Price = val
-				}
-			}
-			items = append(items, item)
-		}
+	if history.Points == nil {
+		history.Points = []*MarketItemPrice{}
 	}
-	return items, nil
-}
 
-func (session *Session) GetMarketItemPriceOverview(appID uint64, country, currencyID, marketHashName string) (*MarketItemPriceOverview, error) {
-	resp, err := session.client.Get("https://steamcommunity.com/market/priceoverview/?" + url.Values{
-		"appid":            {strconv.FormatUint(appID, 10)},
-		"country":          {country},
-		"currencyID":       {currencyID},
-		"market_hash_name": {marketHashName},
-	}.Encode())
-	if resp != nil {
-		defer resp.Body.Close()
+	if prefix := strings.TrimSpace(response.PricePrefix); prefix != "" {
+		_, history.Currency = matchCurrencySymbol(prefix, false)
 	}
 
-	if err != nil {
-		return nil, err
+	if suffix := strings.TrimSpace(response.PriceSuffix); history.Currency == "" && suffix != "" {
+		_, history.Currency = matchCurrencySymbol(suffix, true)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
-	}
+	return history, nil
+}
 
-	overview := &MarketItemPriceOverview{}
-	if err = json.NewDecoder(resp.Body).Decode(overview); err != nil {
+// GetMarketItemOrdersHistogram returns the buy and sell order book of an item,
+// itemNameID can be found with GetItemNameID.
+func (session *Session) GetMarketItemOrdersHistogram(itemNameID uint64, currency, country string) (*MarketItemOrdersHistogram, error) {
+	histogram := &MarketItemOrdersHistogram{}
+	if err := session.getJSON(context.Background(), http.MethodGet, ordersHistogramEndpoint+url.Values{
+		"country":     {country},
+		"language":    {session.language},
+		"currency":    {currency},
+		"item_nameid": {strconv.FormatUint(itemNameID, 10)},
+		"two_factor":  {"0"},
+	}.Encode(), nil, "", histogram); err != nil {
 		return nil, err
 	}
 
-	return overview, nil
+	if histogram.Success != 1 {
+		return nil, ErrCannotLoadPrices
+	}
+
+	return histogram, nil
 }
 
-func (session *Session) SellItem(item *InventoryItem, amount, price uint64) (*MarketSellResponse, error) {
-	req, err := http.NewRequest(
-		http.MethodPost,
-		"https://steamcommunity.com/market/sellitem/",
-		strings.NewReader(url.Values{
-			"amount":    {strconv.FormatUint(amount, 10)},
-			"appid":     {strconv.FormatUint(uint64(item.AppID), 10)},
-			"assetid":   {strconv.FormatUint(item.AssetID, 10)},
-			"contextid": {strconv.FormatUint(item.ContextID, 10)},
-			"price":     {strconv.FormatUint(price, 10)},
-			"sessionid": {session.sessionID},
-		}.Encode()),
-	)
-	if err != nil {
-		return nil, err
+// GetItemNameID scrapes the listing page of an item for its item_nameid,
+// the numeric id required by the order book endpoints.
+// IDs never change, so they are cached for the lifetime of the session.
+func (session *Session) GetItemNameID(appID uint64, marketHashName string) (uint64, error) {
+	key := strconv.FormatUint(appID, 10) + "/" + marketHashName
+
+	session.itemNameIDsMu.Lock()
+	id, ok := session.itemNameIDs[key]
+	session.itemNameIDsMu.Unlock()
+
+	if ok {
+		return id, nil
 	}
 
-	profileURL, err := session.GetProfileURL()
+	id, err := session.fetchItemNameID(appID, marketHashName)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	req.Header.Add("Referer", profileURL+"inventory/")
-
-	resp, err := session.client.Do(req)
-	if resp != nil {
-		defer resp.Body.Close()
+	session.itemNameIDsMu.Lock()
+	if session.itemNameIDs == nil {
+		session.itemNameIDs = make(map[string]uint64)
 	}
+	session.itemNameIDs[key] = id
+	session.itemNameIDsMu.Unlock()
+
+	return id, nil
+}
+
+// MarketListingURL returns the URL of the market page of an item.
+func MarketListingURL(appID uint64, marketHashName string) string {
+	return fmt.Sprintf(marketListingEndpoint, SteamcommunityURL, appID, url.PathEscape(marketHashName))
+}
 
+func (session *Session) fetchItemNameID(appID uint64, marketHashName string) (uint64, error) {
+	body, err := session.fetchListingPage(appID, marketHashName)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("http error: %d", resp.StatusCode)
+	m := itemNameIDRegexp.FindSubmatch(body)
+	if m == nil {
+		return 0, ErrCannotFindItemNameID
 	}
 
-	response := &MarketSellResponse{}
-	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
+	return strconv.ParseUint(string(m[1]), 10, 64)
+}
+
+func (session *Session) fetchListingPage(appID uint64, marketHashName string) ([]byte, error) {
+	resp, err := session.doRequest(context.Background(), http.MethodGet, MarketListingURL(appID, marketHashName), nil, "")
+	if err != nil {
 		return nil, err
 	}
+	defer resp.Body.Close()
 
-	return response, nil
+	return io.ReadAll(resp.Body)
 }
 
-func (session *Session) PlaceBuyOrder(appid uint64, priceTotal float64, quantity uint64, currencyID, marketHashName string) (*MarketBuyOrderResponse, error) {
-	req, err := http.NewRequest(
-		http.MethodPost,
-		"https://steamcommunity.com/market/createbuyorder/",
-		strings.NewReader(url.Values{
-			"appid":            {strconv.FormatUint(appid, 10)},
-			"currency":         {currencyID},
-			"market_hash_name": {marketHashName},
-			"price_total":      {strconv.FormatUint(uint64(priceTotal*100), 10)},
-			"quantity":         {strconv.FormatUint(quantity, 10)},
-			"sessionid":        {session.sessionID},
-		}.Encode()),
-	)
+// GetListingPage loads the listings page of an item and parses the sell listings
+// and assets it embeds, prices are in the currency of the session's wallet.
+func (session *Session) GetListingPage(appID uint64, marketHashName string) (*ListingPage, error) {
+	body, err := session.fetchListingPage(appID, marketHashName)
 	if err != nil {
 		return nil, err
 	}
 
-	var referer string
-	referer = strings.Replace(marketHashName, " ", "%20", -1)
-	referer = strings.Replace(referer, "#", "%23", -1)
+	page := &ListingPage{
+		Listings: []*MarketListingInfo{},
+		Assets:   map[string]map[string]map[string]Asset{},
+	}
 
-	req.Header.Add(
-		"Referer",
-		fmt.Sprintf("https://steamcommunity.com/market/listings/%d/%s", appid, referer),
-	)
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	if m := itemNameIDRegexp.FindSubmatch(body); m != nil {
+		page.ItemNameID, _ = strconv.ParseUint(string(m[1]), 10, 64)
+	}
 
-	resp, err := session.client.Do(req)
-	if resp != nil {
-		defer resp.Body.Close()
+	m := listingInfoRegexp.FindSubmatch(body)
+	if m == nil {
+		return nil, ErrCannotLoadListings
 	}
 
-	if err != nil {
-		return nil, err
+	/* Both are sent as [] rather than {} when there is no listing.  */
+	if string(m[1]) != "[]" {
+		listings := map[string]*MarketListingInfo{}
+		if err = json.Unmarshal(m[1], &listings); err != nil {
+			return nil, err
+		}
+
+		for _, listing := range listings {
+			page.Listings = append(page.Listings, listing)
+		}
 	}
 
-	response := &MarketBuyOrderResponse{}
-	if err = json.NewDecoder(resp.Body).Decode(response); err != nil {
-		return nil, err
+	if m := listingAssetsRegexp.FindSubmatch(body); m != nil && string(m[1]) != "[]" {
+		if err = json.Unmarshal(m[1], &page.Assets); err != nil {
+			return nil, err
+		}
 	}
 
-	return response, nil
+	sortListings(page.Listings)
+
+	return page, nil
 }
 
-func (session *Session) CancelBuyOrder(orderid uint64) error {
-	req, err := http.NewRequest(
-		http.MethodPost,
-		"https://steamcommunity.com/market/cancelbuyorder/",
-		strings.NewReader(url.Values{
-			"sessionid":   {session.sessionID},
-			"buy_orderid": {strconv.FormatUint(orderid, 10)},
-		}.Encode()),
-	)
-	if err != nil {
-		return err
-	}
+// sortListings sorts listings cheapest first, as Steam displays them.
+func sortListings(listings []*MarketListingInfo) {
+	sort.Slice(listings, func(i, j int) bool {
+		a, b := listings[i], listings[j]
+		if a.ConvertedPrice+a.ConvertedFee != b.ConvertedPrice+b.ConvertedFee {
+			return a.ConvertedPrice+a.ConvertedFee < b.ConvertedPrice+b.ConvertedFee
+		}
 
-	req.Header.Add("Referer", "https://steamcommunity.com/market")
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		return a.ListingID < b.ListingID
+	})
+}
 
-	resp, err := session.client.Do(req)
-	if resp != nil {
-		resp.Body.Close()
+// GetListingsForItem returns count public sell listings of an item starting at start,
+// Steam serves up to 100 of them per request.
+func (session *Session) GetListingsForItem(appID uint64, marketHashName string, start, count uint64) (*ItemListings, error) {
+	type Response struct {
+		Success     bool            `json:"success"`
+		Start       uint64          `json:"start"`
+		PageSize    uint64          `json:"pagesize"`
+		TotalCount  uint64          `json:"total_count"`
+		ListingInfo json.RawMessage `json:"listinginfo"`
+		Assets      json.RawMessage `json:"assets"`
 	}
 
-	if err != nil {
-		return err
+	var response Response
+	if err := session.getJSON(context.Background(), http.MethodGet, MarketListingURL(appID, marketHashName)+"/render/?"+url.Values{
+		"query":    {""},
+		"start":    {strconv.FormatUint(start, 10)},
+		"count":    {strconv.FormatUint(count, 10)},
+		"language": {session.language},
+	}.Encode(), nil, "", &response); err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("cannot cancel %d: %d", orderid, resp.StatusCode)
+	if !response.Success {
+		return nil, ErrCannotLoadListings
 	}
 
-	return nil
-}
+	listings := &ItemListings{
+		Start:      response.Start,
+		TotalCount: response.TotalCount,
+		Listings:   []*MarketListingInfo{},
+		Assets:     map[string]map[string]map[string]Asset{},
+	}
 
-func (session *Session) GetWallet() (string, error) {
-	client := &http.Client{}
+	/* Both are sent as [] rather than {} when there is no listing.  */
+	if len(response.ListingInfo) != 0 && string(response.ListingInfo) != "[]" {
+		info := map[string]*MarketListingInfo{}
+		if err := json.Unmarshal(response.ListingInfo, &info); err != nil {
+			return nil, err
+		}
 
-	req, err := http.NewRequest(http.MethodGet, SteamcommunityURL, nil)
-	if err != nil {
-		return "", err
+		for _, listing := range info {
+			listings.Listings = append(listings.Listings, listing)
+		}
 	}
 
-	parsedURL, err := url.Parse(SteamcommunityURL)
-	if err != nil {
-		return "", err
+	if len(response.Assets) != 0 && string(response.Assets) != "[]" {
+		if err := json.Unmarshal(response.Assets, &listings.Assets); err != nil {
+			return nil, err
+		}
 	}
 
-	for _, v := range session.client.Jar.Cookies(parsedURL) {
-		if v.Name == "steamLoginSecure" {
-			req.Header.Set("Cookie", v.String())
-		}
+	sortListings(listings.Listings)
+
+	return listings, nil
+}
+
+// ParsePriceHistoryDate parses the dates of the price history, e.g. "Dec 10 2021 01: +0",
+// which carry the hour and its offset to UTC in hours.
+func ParsePriceHistoryDate(date string) (time.Time, error) {
+	i := strings.LastIndex(date, ":")
+	if i == -1 {
+		return time.Time{}, fmt.Errorf("invalid price history date: %q", date)
 	}
 
-	resp, err := client.Do(req)
+	t, err := time.ParseInLocation("Jan 02 2006 15", strings.TrimSpace(date[:i]), time.UTC)
 	if err != nil {
-		return "", err
+		return time.Time{}, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	offset, err := strconv.Atoi(strings.TrimSpace(date[i+1:]))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid price history date: %q", date)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return "", err
+	return t.Add(-time.Duration(offset) * time.Hour), nil
+}
+
+// AggregatePriceHistory groups points into OHLC buckets of the given duration
+// (e.g. 24 * time.Hour), buckets are aligned on UTC and sorted by time.
+func AggregatePriceHistory(points []*MarketItemPrice, bucket time.Duration) ([]*PriceHistoryBucket, error) {
+	type point struct {
+		date  time.Time
+		price float64
+		count uint64
 	}
 
-	wallet := ""
-	doc.Find(".responsive_menu_user_wallet").Each(func(i int, s *goquery.Selection) {
-		wallet = s.Find("b").Text()
-	})
+	sorted := make([]point, 0, len(points))
+	for _, p := range points {
+		if !p.ParsedDate.IsZero() {
+			sorted = append(sorted, point{p.ParsedDate, p.Price, uint64(p.CountInt)})
+			continue
+		}
 
-	if wallet == "" {
-		return "", fmt.Errorf("failed to get wallet")
+		date, err := ParsePriceHistoryDate(p.Date)
+		if err != nil {
+			return nil, err
+		}
+
+		count, err := strconv.ParseUint(p.Count, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid price history volume %q: %v", p.Count, err)
+		}
+
+		sorted = append(sorted, point{date, p.Price, count})
 	}
 
-	return wallet, nil
-}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].date.Before(sorted[j].date)
+	})
 
-func (session *Session) CleanPrice(price string) (string, string, string) {
-	currencyRe := regexp.MustCompile(`[^\p{L}\p{Sc}]`)
-	currencySymbol := strings.TrimSpace(currencyRe.ReplaceAllString(price, ""))
+	buckets := []*PriceHistoryBucket{}
+	var current *PriceHistoryBucket
+	for _, p := range sorted {
+		start := p.date.Truncate(bucket)
+		if current == nil || !current.Start.Equal(start) {
+			current = &PriceHistoryBucket{
+				Start: start,
+				Open:  p.price,
+				High:  p.price,
+				Low:   p.price,
+			}
+			buckets = append(buckets, current)
+		}
 
-	numericRe := regexp.MustCompile(`[^\d,.]`)
-	cleanedPrice := numericRe.ReplaceAllString(price, "")
+		if p.price > current.High {
+			current.High = p.price
+		}
 
-	cleanedPrice = strings.ReplaceAll(cleanedPrice, " ", "")
-	cleanedPrice = strings.TrimSpace(cleanedPrice)
+		if p.price < current.Low {
+			current.Low = p.price
+		}
 
-	currencyID := ""
-	if id, found := WalletMap[currencySymbol]; found {
-		currencyID = id
+		current.Close = p.price
+		current.Volume += p.count
 	}
 
-	return cleanedPrice, currencySymbol, currencyID
+	return buckets, nil
 }
 
-func (session *Session) GetMyListingsItems(start, perPage uint64) (*ListingItem, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(myListingItemsEndpoint, SteamcommunityURL, start, perPage), nil)
-	if err != nil {
-		return nil, err
+// GetMarketItemPriceOverview returns the lowest and median price of an item.
+// currencyID must be one of the Currency* constants, an empty country is derived from it.
+// When both are empty, the account's wallet currency and country are used, see GetWalletCurrencyID.
+func (session *Session) GetMarketItemPriceOverview(appID uint64, country, currencyID, marketHashName string) (*MarketItemPriceOverview, error) {
+	return session.getPriceOverview(context.Background(), appID, country, currencyID, marketHashName, nil)
+}
+
+// getPriceOverview is GetMarketItemPriceOverview waiting for limiter, if any, before
+// requesting an overview that is not cached.
+func (session *Session) getPriceOverview(ctx context.Context, appID uint64, country, currencyID, marketHashName string, limiter RateLimiter) (*MarketItemPriceOverview, error) {
+	if currencyID == "" && country == "" {
+		var err error
+		if currencyID, country, err = session.getWalletLocale(); err != nil {
+			return nil, err
+		}
 	}
 
-	resp, err := session.client.Do(req)
-	if err != nil {
-		return nil, err
+	if _, ok := CurrencyCodes[currencyID]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCurrency, currencyID)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if country == "" {
+		country = CountryForCurrency(currencyID)
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	key := priceOverviewKey{appID, currencyID, country, marketHashName}
+	if overview, ok := session.cachedPriceOverview(key); ok {
+		return overview, nil
 	}
 
-	var listingItems ListingItem
-	if err := json.Unmarshal(body, &listingItems); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal json: %v", err)
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
 	}
 
-	return &listingItems, nil
-}
+	overview := &MarketItemPriceOverview{}
+	if err := session.getJSON(ctx, http.MethodGet, "https://steamcommunity.com/market/priceoverview/?"+url.Values{
+		"appid":            {strconv.FormatUint(appID, 10)},
+		"country":          {country},
+		"currencyID":       {currencyID},
+		"market_hash_name": {marketHashName},
+	}.Encode(), nil, "", overview); err != nil {
+		return nil, err
+	}
 
-func (s *Session) GetMarketItems(appid, start, perPage uint64) (*SteamMarketItems, error) {
-	client := http.Client{}
-	endpoint := fmt.Sprintf(marketEndpoint, SteamcommunityURL, appid, start, perPage)
+	/* Cents stay zero on an unexpected format, ParseError tells why.  */
+	overview.ParseError = overview.ParsePricesForCurrency(currencyID)
+	session.cachePriceOverview(key, overview)
+
+	return overview, nil
+}
+
+// GetLowestPrice returns the lowest sell price of an item in cents of currencyID
+// (the wallet currency when empty).
+func (session *Session) GetLowestPrice(appID uint64, marketHashName, currencyID string) (int64, error) {
+	overview, err := session.GetMarketItemPriceOverview(appID, "", currencyID, marketHashName)
+	if err != nil {
+		return 0, err
+	}
+
+	if !overview.Success {
+		return 0, ErrCannotLoadPrices
+	}
+
+	if overview.LowestPrice == "" {
+		return 0, ErrNoLowestPrice
+	}
+
+	if overview.ParseError != nil {
+		return 0, overview.ParseError
+	}
+
+	return overview.LowestPriceCents, nil
+}
+
+// GetPriceOverviews loads the price overview of every distinct name in names, one request
+// after the other.  They are spaced by the session's rate limiter for steamcommunity.com
+// (see SetRateLimiter), or by three seconds without one, cached overviews are not requested.
+// The overviews loaded are returned along with the failures, both keyed by name.  Once ctx
+// is done, the names left are reported as failed with ctx.Err().
+func (session *Session) GetPriceOverviews(ctx context.Context, appID uint64, names []string, currencyID string) (map[string]*MarketItemPriceOverview, map[string]error) {
+	var limiter RateLimiter
+	if session.rateLimiter("steamcommunity.com") == nil {
+		limiter = NewTokenBucket(1/priceOverviewInterval.Seconds(), 1)
+	}
+
+	overviews := make(map[string]*MarketItemPriceOverview)
+	failures := make(map[string]error)
+	for _, name := range names {
+		if _, ok := overviews[name]; ok {
+			continue
+		}
+
+		if _, ok := failures[name]; ok {
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			failures[name] = err
+			continue
+		}
+
+		overview, err := session.getPriceOverview(ctx, appID, "", currencyID, name, limiter)
+		if err != nil {
+			failures[name] = err
+			continue
+		}
 
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+		overviews[name] = overview
+	}
+
+	return overviews, failures
+}
+
+func (session *Session) SellItem(item *InventoryItem, amount, price uint64) (*MarketSellResponse, error) {
+	return session.SellAsset(item.AppID, item.ContextID, item.AssetID, amount, price)
+}
+
+// SellAsset is like SellItem for an asset known only by its identifiers,
+// price is what the seller receives in cents, see CalculateBuyerPays.
+func (session *Session) SellAsset(appID uint32, contextID, assetID, amount, price uint64) (*MarketSellResponse, error) {
+	return session.sellAsset(context.Background(), appID, contextID, assetID, amount, price)
+}
+
+func (session *Session) sellAsset(ctx context.Context, appID uint32, contextID, assetID, amount, price uint64) (*MarketSellResponse, error) {
+	profileURL, err := session.GetProfileURL()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("cannot resolve profile URL: %w", err)
+	}
+
+	response := &MarketSellResponse{}
+	if err = session.getJSON(ctx, http.MethodPost, "https://steamcommunity.com/market/sellitem/", url.Values{
+		"amount":    {strconv.FormatUint(amount, 10)},
+		"appid":     {strconv.FormatUint(uint64(appID), 10)},
+		"assetid":   {strconv.FormatUint(assetID, 10)},
+		"contextid": {strconv.FormatUint(contextID, 10)},
+		"price":     {strconv.FormatUint(price, 10)},
+		"sessionid": {session.sessionID},
+	}, profileURL+"inventory/", response); err != nil {
+		return nil, err
 	}
 
-	parsedURL, err := url.Parse(SteamcommunityURL)
+	return response, nil
+}
+
+// SellResult is the outcome of selling one item with SellItems.
+type SellResult struct {
+	Item      *InventoryItem
+	Response  *MarketSellResponse // nil when Err is set
+	Err       error
+	ListingID string // Of listings awaiting confirmation, once found
+	Confirmed bool
+}
+
+// SellItems lists every item, its whole stack, at price (what the seller receives in cents),
+// one after the other: use SetRateLimiter to space the requests.  Unless identitySecret is empty,
+// the confirmations of the listings created, and of no other, are then accepted in a single
+// request per attempt, as their listings show up.  Selling and confirming stop once ctx is done.
+// Results follow the order of items, the error is only set when confirming fails.
+func (session *Session) SellItems(ctx context.Context, items []*InventoryItem, price uint64, identitySecret string) ([]SellResult, error) {
+	results := make([]SellResult, len(items))
+	pending := make(map[uint64][]*SellResult)
+	for i, item := range items {
+		results[i].Item = item
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		amount := item.Amount
+		if amount == 0 {
+			amount = 1
+		}
+
+		response, err := session.sellAsset(ctx, item.AppID, item.ContextID, item.AssetID, amount, price)
+		if err == nil && !response.Success {
+			err = fmt.Errorf("%w: %s", ErrCannotSellItem, response.Message)
+		}
+
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		results[i].Response = response
+		if response.MobileConfirmationRequired {
+			pending[item.AssetID] = append(pending[item.AssetID], &results[i])
+		}
+	}
+
+	if len(pending) == 0 || identitySecret == "" {
+		return results, nil
+	}
+
+	if err := session.confirmSoldListings(ctx, pending, identitySecret); err != nil {
+		return results, fmt.Errorf("cannot confirm listings: %w", err)
+	}
+
+	return results, nil
+}
+
+// confirmSoldListings accepts the confirmations of the listings created for the pending
+// results, keyed by asset ID, and nothing else.
+func (session *Session) confirmSoldListings(ctx context.Context, pending map[uint64][]*SellResult, identitySecret string) error {
+	for attempt := 0; attempt < sellConfirmationAttempts && len(pending) != 0; attempt++ {
+		if attempt != 0 {
+			if err := sleepContext(ctx, sellConfirmationInterval); err != nil {
+				return err
+			}
+		}
+
+		assetIDs := make([]uint64, 0, len(pending))
+		for id := range pending {
+			assetIDs = append(assetIDs, id)
+		}
+
+		listingIDs, err := session.findListingsToConfirm(assetIDs)
+		if err != nil {
+			return err
+		}
+
+		byListing := make(map[string]uint64, len(listingIDs))
+		for assetID, listingID := range listingIDs {
+			for _, result := range pending[assetID] {
+				result.ListingID = listingID
+			}
+			byListing[listingID] = assetID
+		}
+
+		if len(byListing) == 0 {
+			continue
+		}
+
+		confirmations, err := session.fetchConfirmations(ctx, identitySecret)
+		if err != nil {
+			return err
+		}
+
+		matching := []*Confirmation{}
+		for _, conf := range confirmations.Confirmations {
+			if _, ok := byListing[conf.Creator]; ok {
+				matching = append(matching, conf)
+			}
+		}
+
+		if len(matching) == 0 {
+			continue
+		}
+
+		result, err := session.SendMultiConfirmationAjax(matching, ConfirmationAccept, identitySecret)
+		if err != nil {
+			return err
+		}
+
+		if !result.Success {
+			return fmt.Errorf("cannot accept %d confirmations", len(matching))
+		}
+
+		for _, conf := range matching {
+			assetID := byListing[conf.Creator]
+			for _, result := range pending[assetID] {
+				result.Confirmed = true
+			}
+			delete(pending, assetID)
+		}
+	}
+
+	if len(pending) != 0 {
+		return fmt.Errorf("%w: %d listings left unconfirmed", ErrCannotFindConfirmations, len(pending))
+	}
+
+	return nil
+}
+
+// SellItemAndConfirm sells item and, when Steam asks for a mobile confirmation,
+// waits for the confirmation of this very listing to show up and accepts it.
+func (session *Session) SellItemAndConfirm(item *InventoryItem, amount, price uint64, identitySecret string) (*MarketSellResponse, error) {
+	return session.SellItemAndConfirmContextAware(context.Background(), item, amount, price, identitySecret)
+}
+
+// SellItemAndConfirmContextAware is like SellItemAndConfirm, but waiting for the confirmation
+// stops as soon as ctx is done.
+func (session *Session) SellItemAndConfirmContextAware(ctx context.Context, item *InventoryItem, amount, price uint64, identitySecret string) (*MarketSellResponse, error) {
+	response, err := session.SellItem(item, amount, price)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, v := range s.client.Jar.Cookies(parsedURL) {
-		if v.Name == "steamLoginSecure" {
-			req.Header.Set("Cookie", v.String())
+	if !response.Success || !response.MobileConfirmationRequired {
+		return response, nil
+	}
+
+	for attempt := 0; attempt < sellConfirmationAttempts; attempt++ {
+		if attempt != 0 {
+			if err := sleepContext(ctx, sellConfirmationInterval); err != nil {
+				return response, err
+			}
+		}
+
+		listingIDs, err := session.findListingsToConfirm([]uint64{item.AssetID})
+		if err != nil {
+			return response, err
+		}
+
+		listingID, ok := listingIDs[item.AssetID]
+		if !ok {
+			continue
+		}
+
+		confirmations, err := session.FetchConfirmations(identitySecret)
+		if err != nil {
+			return response, err
+		}
+
+		for _, conf := range confirmations.Confirmations {
+			if conf.Creator != listingID {
+				continue
+			}
+
+			result, err := session.SendConfirmationAjax(conf, ConfirmationAccept, identitySecret)
+			if err != nil {
+				return response, err
+			}
+
+			if !result.Success {
+				return response, fmt.Errorf("cannot confirm listing %s", listingID)
+			}
+
+			return response, nil
 		}
 	}
 
-	resp, err := client.Do(req)
+	return response, ErrCannotFindConfirmations
+}
+
+// findListingsToConfirm returns the IDs of the listings awaiting confirmation created
+// for assetIDs, keyed by asset ID, assets whose listing is not there yet are left out.
+// Listings are only matched by asset ID: the name and price could match another
+// listing of the same item, which must never be confirmed in its place.
+func (session *Session) findListingsToConfirm(assetIDs []uint64) (map[uint64]string, error) {
+	listings, err := session.GetMyListingsItems(0, 100)
 	if err != nil {
 		return nil, err
 	}
 
+	wanted := make(map[string]uint64, len(assetIDs))
+	for _, id := range assetIDs {
+		wanted[strconv.FormatUint(id, 10)] = id
+	}
+
+	found := make(map[uint64]string)
+	for _, listing := range listings.ListingsToConfirm {
+		if id, ok := wanted[listing.Asset.ID]; ok {
+			found[id] = listing.ListingID
+		}
+	}
+
+	return found, nil
+}
+
+// PlaceBuyOrder creates a buy order, when Steam refuses it (ErrCode != 1) the response is
+// returned along with an error wrapping ErrBuyOrderFailed and ErrMsg.
+func (session *Session) PlaceBuyOrder(appid uint64, priceTotal float64, quantity uint64, currencyID, marketHashName string) (*MarketBuyOrderResponse, error) {
+	response := &MarketBuyOrderResponse{}
+	if err := session.getJSON(context.Background(), http.MethodPost, "https://steamcommunity.com/market/createbuyorder/", url.Values{
+		"appid":            {strconv.FormatUint(appid, 10)},
+		"currency":         {currencyID},
+		"market_hash_name": {marketHashName},
+		"price_total":      {strconv.FormatUint(uint64(priceTotal*100), 10)},
+		"quantity":         {strconv.FormatUint(quantity, 10)},
+		"sessionid":        {session.sessionID},
+	}, MarketListingURL(appid, marketHashName), response); err != nil {
+		return nil, err
+	}
+
+	if response.ErrCode != 1 {
+		return response, fmt.Errorf("%w: %s (%d)", ErrBuyOrderFailed, response.ErrMsg, response.ErrCode)
+	}
+
+	return response, nil
+}
+
+// GetBuyOrderStatus returns how much of the buy order orderID has been filled.
+func (session *Session) GetBuyOrderStatus(orderID uint64) (*BuyOrderStatus, error) {
+	status := &BuyOrderStatus{}
+	if err := session.getJSON(context.Background(), http.MethodGet, "https://steamcommunity.com/market/getbuyorderstatus/?"+url.Values{
+		"sessionid":   {session.sessionID},
+		"buy_orderid": {strconv.FormatUint(orderID, 10)},
+	}.Encode(), nil, "https://steamcommunity.com/market", status); err != nil {
+		return nil, err
+	}
+
+	if status.Success != 1 {
+		return nil, ErrCannotLoadBuyOrder
+	}
+
+	return status, nil
+}
+
+func (session *Session) CancelBuyOrder(orderid uint64) error {
+	resp, err := session.doRequest(context.Background(), http.MethodPost, "https://steamcommunity.com/market/cancelbuyorder/", url.Values{
+		"sessionid":   {session.sessionID},
+		"buy_orderid": {strconv.FormatUint(orderid, 10)},
+	}, "https://steamcommunity.com/market")
+	if err != nil {
+		return fmt.Errorf("cannot cancel %d: %w", orderid, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (session *Session) CancelSellListing(listingID string) error {
+	resp, err := session.doRequest(context.Background(), http.MethodPost, "https://steamcommunity.com/market/removelisting/"+listingID, url.Values{
+		"sessionid": {session.sessionID},
+	}, "https://steamcommunity.com/market")
+	if err != nil {
+		return fmt.Errorf("cannot remove listing %s: %w", listingID, err)
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+func (session *Session) GetWallet() (string, error) {
+	resp, err := session.doRequest(context.Background(), http.MethodGet, SteamcommunityURL, nil, "")
+	if err != nil {
+		return "", err
+	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return "", err
 	}
 
+	wallet := ""
+	doc.Find(".responsive_menu_user_wallet").Each(func(i int, s *goquery.Selection) {
+		wallet = s.Find("b").Text()
+	})
+
+	if wallet == "" {
+		return "", fmt.Errorf("failed to get wallet")
+	}
+
+	return wallet, nil
+}
+
+// GetWalletBalance returns the wallet balance in cents along with its currency.
+func (session *Session) GetWalletBalance() (*WalletInfo, error) {
+	wallet, err := session.GetWallet()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &WalletInfo{Balance: wallet}
+
+	/* Some currencies are displayed with their code, e.g. "$12.34 USD".  */
+	price := strings.TrimSpace(wallet)
+	if fields := strings.Fields(price); len(fields) > 1 {
+		last := fields[len(fields)-1]
+		for id, code := range CurrencyCodes {
+			if code == last {
+				info.CurrencyID, info.CurrencyCode = id, code
+				price = strings.TrimSpace(strings.TrimSuffix(price, last))
+				break
+			}
+		}
+	}
+
+	_, _, currencyID := session.CleanPrice(price)
+	if info.CurrencyID == "" {
+		info.CurrencyID = currencyID
+		info.CurrencyCode = CurrencyCodes[currencyID]
+	}
+
+	if info.BalanceCents, err = ParsePriceCents(price); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// GetWalletCurrencyID returns the currency ID of the account's wallet, e.g. CurrencyEUR.
+// It is looked up once and then cached along with the account's country.
+func (session *Session) GetWalletCurrencyID() (string, error) {
+	currencyID, _, err := session.getWalletLocale()
+	return currencyID, err
+}
+
+// GetWalletCountry returns the country code of the account, e.g. "DE", see GetWalletCurrencyID.
+func (session *Session) GetWalletCountry() (string, error) {
+	_, country, err := session.getWalletLocale()
+	return country, err
+}
+
+func (session *Session) getWalletLocale() (string, string, error) {
+	session.walletMu.Lock()
+	defer session.walletMu.Unlock()
+
+	if session.walletCurrencyID != "" {
+		return session.walletCurrencyID, session.walletCountry, nil
+	}
+
+	currencyID, country, err := session.fetchWalletLocale()
+	if err != nil {
+		return "", "", err
+	}
+
+	session.walletCurrencyID, session.walletCountry = currencyID, country
+	return currencyID, country, nil
+}
+
+func (session *Session) resetWalletLocale() {
+	session.walletMu.Lock()
+	session.walletCurrencyID, session.walletCountry = "", ""
+	session.walletMu.Unlock()
+}
+
+func (session *Session) fetchWalletLocale() (string, string, error) {
+	resp, err := session.doRequest(context.Background(), http.MethodGet, SteamcommunityURL+"market/", nil, "")
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return "", "", err
+	}
+
+	if m := walletInfoRegexp.FindSubmatch(body); m != nil {
+		var info struct {
+			Currency int    `json:"wallet_currency"`
+			Country  string `json:"wallet_country"`
+		}
+
+		if json.Unmarshal(m[1], &info) == nil && info.Currency != 0 {
+			currencyID := strconv.Itoa(info.Currency)
+			if info.Country == "" {
+				info.Country = CountryForCurrency(currencyID)
+			}
+
+			return currencyID, info.Country, nil
+		}
+	}
+
+	/* Fall back to recognizing the currency of the displayed balance.  */
+	wallet, err := session.GetWalletBalance()
+	if err != nil {
+		return "", "", err
+	}
+
+	if wallet.CurrencyID == "" {
+		return "", "", fmt.Errorf("%w: %q", ErrUnknownCurrency, wallet.Balance)
+	}
+
+	return wallet.CurrencyID, CountryForCurrency(wallet.CurrencyID), nil
+}
+
+// MarketEligibility tells whether the account may use the market.
+type MarketEligibility struct {
+	Allowed bool
+	Reason  string    // Warning shown by Steam when not allowed
+	Until   time.Time // When the restriction ends, zero if unknown or permanent
+}
+
+// GetMarketEligibility reads the market page for the warning Steam shows to restricted
+// accounts.  When the account is not allowed to use the market, the eligibility is
+// returned together with an error wrapping ErrMarketNotEligible.
+func (session *Session) GetMarketEligibility() (*MarketEligibility, error) {
+	resp, err := session.doRequest(context.Background(), http.MethodGet, SteamcommunityURL+"market/", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	warning := doc.Find(".market_headertip_container_warning").First()
+	if warning.Length() == 0 {
+		return &MarketEligibility{Allowed: true}, nil
+	}
+
+	reason := warning.Find(".market_warning_header").Text()
+	if strings.TrimSpace(reason) == "" {
+		reason = warning.Text()
+	}
+
+	eligibility := &MarketEligibility{
+		Reason: strings.Join(strings.Fields(reason), " "),
+		Until:  parseMarketUnlock(strings.Join(strings.Fields(warning.Text()), " ")),
+	}
+
+	if eligibility.Until.IsZero() {
+		return eligibility, ErrMarketNotEligible
+	}
+
+	return eligibility, fmt.Errorf("%w until %s", ErrMarketNotEligible, eligibility.Until.Format("Jan 2, 2006"))
+}
+
+// parseMarketUnlock finds the date the market can be used again in the text of the warning,
+// dates without a year are the next such day.
+func parseMarketUnlock(text string) time.Time {
+	m := marketUnlockRegexp.FindStringSubmatch(text)
+	if m == nil {
+		return time.Time{}
+	}
+
+	for _, layout := range []string{"Jan 2, 2006", "January 2, 2006"} {
+		if t, err := time.Parse(layout, m[1]); err == nil {
+			return t
+		}
+	}
+
+	for _, layout := range []string{"Jan 2", "January 2"} {
+		if t, err := time.Parse(layout, m[1]); err == nil {
+			now := time.Now()
+			t = t.AddDate(now.Year(), 0, 0)
+			if t.Before(now.AddDate(0, 0, -1)) {
+				t = t.AddDate(1, 0, 0)
+			}
+
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (session *Session) CleanPrice(price string) (string, string, string) {
+	return cleanPrice(price)
+}
+
+// FormatPrice formats cents the way Steam displays amounts of currencyID, e.g. "$1,234.56",
+// "1 234,56€" or "¥ 1,234". Unknown currencies are formatted without a symbol.
+func FormatPrice(cents int64, currencyID string) string {
+	format, ok := currencyFormats[currencyID]
+	if !ok {
+		format = currencyFormat{decimal: ".", group: ","}
+	}
+
+	sign := ""
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+
+	whole := strconv.FormatInt(cents/100, 10)
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + format.group + whole[i:]
+	}
+
+	amount := whole
+	if !format.noDecimals {
+		amount += format.decimal + fmt.Sprintf("%02d", cents%100)
+	}
+
+	separator := ""
+	if format.space {
+		separator = " "
+	}
+
+	switch {
+	case format.symbol == "":
+		return sign + amount
+	case format.suffix:
+		return sign + amount + separator + format.symbol
+	default:
+		return sign + format.symbol + separator + amount
+	}
+}
+
+func cleanPrice(price string) (string, string, string) {
+	first := strings.IndexFunc(price, unicode.IsDigit)
+	if first == -1 {
+		return "", strings.TrimSpace(price), ""
+	}
+	last := strings.LastIndexFunc(price, unicode.IsDigit)
+
+	/* Only keep the amount itself, symbols such as "pуб." or "S/." hold dots too.  */
+	cleanedPrice := priceNumericRegexp.ReplaceAllString(price[first:last+1], "")
+
+	/* Symbols sit either before or after the amount, look at the side they are on
+	   so that e.g. "R$ 1,23" is not mistaken for "R 1.23".  */
+	prefix := strings.Trim(price[:first], "- ")
+	suffix := strings.TrimSpace(strings.TrimLeft(price[last+1:], ",.- "))
+
+	if prefix != "" {
+		if symbol, id := matchCurrencySymbol(prefix, false); id != "" {
+			return cleanedPrice, symbol, id
+		}
+	}
+
+	if suffix != "" {
+		if symbol, id := matchCurrencySymbol(suffix, true); id != "" {
+			return cleanedPrice, symbol, id
+		}
+	}
+
+	return cleanedPrice, strings.TrimSpace(priceCurrencyRegexp.ReplaceAllString(price, "")), ""
+}
+
+type currencySymbol struct {
+	symbol     string
+	currencyID string
+	suffix     bool
+	anywhere   bool // From WalletMap, which does not tell the placement
+}
+
+// currencySymbols lists every known symbol, longest first.
+var currencySymbols = func() []currencySymbol {
+	symbols := []currencySymbol{}
+	for symbol, id := range WalletMap {
+		symbols = append(symbols, currencySymbol{symbol: symbol, currencyID: id, anywhere: true})
+	}
+
+	for id, format := range currencyFormats {
+		symbols = append(symbols, currencySymbol{symbol: format.symbol, currencyID: id, suffix: format.suffix})
+	}
+
+	sort.Slice(symbols, func(i, j int) bool {
+		a, b := symbols[i], symbols[j]
+		if len(a.symbol) != len(b.symbol) {
+			return len(a.symbol) > len(b.symbol)
+		}
+
+		if a.anywhere != b.anywhere {
+			return a.anywhere
+		}
+
+		x, _ := strconv.Atoi(a.currencyID)
+		y, _ := strconv.Atoi(b.currencyID)
+		return x < y
+	})
+
+	return symbols
+}()
+
+// matchCurrencySymbol finds the currency of text, the part of a price before (or after when suffix is set)
+// the amount. An exact match wins, otherwise the longest symbol text starts (or ends) with is used.
+func matchCurrencySymbol(text string, suffix bool) (string, string) {
+	if id, ok := WalletMap[text]; ok {
+		return text, id
+	}
+
+	for _, s := range currencySymbols {
+		if s.symbol == text && (s.anywhere || s.suffix == suffix) {
+			return s.symbol, s.currencyID
+		}
+	}
+
+	for _, s := range currencySymbols {
+		if !s.anywhere && s.suffix != suffix {
+			continue
+		}
+
+		if (!suffix && strings.HasPrefix(text, s.symbol)) || (suffix && strings.HasSuffix(text, s.symbol)) {
+			return s.symbol, s.currencyID
+		}
+	}
+
+	return "", ""
+}
+
+func (session *Session) GetMyListingsItems(start, perPage uint64) (*ListingItem, error) {
+	var listingItems ListingItem
+	if err := session.getJSON(context.Background(), http.MethodGet, fmt.Sprintf(myListingItemsEndpoint, SteamcommunityURL, start, perPage), nil, "", &listingItems); err != nil {
+		return nil, err
+	}
+
+	return &listingItems, nil
+}
+
+// GetMyBuyOrders returns the account's active buy orders.
+func (session *Session) GetMyBuyOrders() ([]BuyOrder, error) {
+	type Response struct {
+		Success   bool       `json:"success"`
+		BuyOrders []BuyOrder `json:"buy_orders"`
+	}
+
+	/* Buy orders are not paginated, every page carries all of them.  */
+	var response Response
+	if err := session.getJSON(context.Background(), http.MethodGet, fmt.Sprintf(myListingItemsEndpoint, SteamcommunityURL, 0, 1), nil, "", &response); err != nil {
+		return nil, err
+	}
+
+	if !response.Success {
+		return nil, ErrCannotLoadListings
+	}
+
+	return response.BuyOrders, nil
+}
+
+// GetMyActiveListings returns the asset IDs of all the account's sell listings keyed by listing ID,
+// including listings that still await confirmation or are on hold.
+// Use it right after SellItem to find the listing ID of the new listing by its asset ID.
+func (session *Session) GetMyActiveListings() (map[string]string, error) {
+	listings := make(map[string]string)
+
+	for start := uint64(0); ; {
+		page, err := session.GetMyListingsItems(start, maxMarketSearchPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, group := range [][]Listing{page.Listings, page.ListingsToConfirm, page.ListingsOnHold} {
+			for _, listing := range group {
+				listings[listing.ListingID] = listing.Asset.ID
+			}
+		}
+
+		start += uint64(len(page.Listings))
+		if len(page.Listings) == 0 || start >= uint64(page.TotalCount) {
+			break
+		}
+	}
+
+	return listings, nil
+}
+
+// RemoveAllListings cancels every sell listing of the account, waiting delay between two cancellations.
+// Failures are returned keyed by listing ID, with stopOnError the first failure ends the run.
+// The error is only set when the listings cannot be enumerated.
+func (session *Session) RemoveAllListings(delay time.Duration, stopOnError bool) (map[string]error, error) {
+	listings, err := session.GetMyActiveListings()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(listings))
+	for id := range listings {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	failures := make(map[string]error)
+	for i, id := range ids {
+		if i != 0 && delay > 0 {
+			time.Sleep(delay)
+		}
+
+		if err := session.CancelSellListing(id); err != nil {
+			failures[id] = err
+			if stopOnError {
+				break
+			}
+		}
+	}
+
+	return failures, nil
+}
+
+func (s *Session) GetMarketItems(appid, start, perPage uint64) (*SteamMarketItems, error) {
+	return s.GetMarketItemsWithQuery(appid, "", nil, start, perPage)
+}
+
+// GetMarketItemsWithQuery searches the market of appid for query (may be empty).
+// filters are passed as-is as tag parameters, e.g.
+//
+//	map[string]string{"category_730_Weapon[]": "tag_weapon_ak47"}
+func (s *Session) GetMarketItemsWithQuery(appid uint64, query string, filters map[string]string, start, count uint64) (*SteamMarketItems, error) {
+	return s.SearchMarketItems(appid, &MarketSearchOptions{Query: query, Filters: filters}, start, count)
+}
+
+// SearchMarketItems searches the market of appid as described by opts, which may be nil.
+func (s *Session) SearchMarketItems(appid uint64, opts *MarketSearchOptions, start, count uint64) (*SteamMarketItems, error) {
+	if opts == nil {
+		opts = &MarketSearchOptions{}
+	}
+
+	params := url.Values{
+		"norender": {"1"},
+		"appid":    {strconv.FormatUint(appid, 10)},
+		"start":    {strconv.FormatUint(start, 10)},
+		"count":    {strconv.FormatUint(count, 10)},
+	}
+	if opts.Query != "" {
+		params.Set("query", opts.Query)
+	}
+	if opts.SearchDescriptions {
+		params.Set("search_descriptions", "1")
+	}
+	for k, v := range opts.Filters {
+		params.Add(k, v)
+	}
+
+	if opts.SortColumn != "" {
+		if !marketSortColumns[opts.SortColumn] {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSortColumn, opts.SortColumn)
+		}
+
+		dir := opts.SortDir
+		if dir == "" {
+			dir = SortAsc
+		}
+
+		if dir != SortAsc && dir != SortDesc {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidSortColumn, dir)
+		}
+
+		params.Set("sort_column", opts.SortColumn)
+		params.Set("sort_dir", dir)
 	}
 
 	var marketItems SteamMarketItems
-	if err := json.Unmarshal(body, &marketItems); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON: %w", err)
+	if err := s.getJSON(context.Background(), http.MethodGet, marketEndpoint+params.Encode(), nil, "", &marketItems); err != nil {
+		return nil, err
 	}
 
 	return &marketItems, nil
 }
+
+// GetMarketItemsCount returns how many items the market of appid lists,
+// asking for an empty page so only the total is sent.
+func (s *Session) GetMarketItemsCount(appid uint64) (int, error) {
+	page, err := s.SearchMarketItems(appid, nil, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	if !page.Success {
+		return 0, ErrCannotLoadListings
+	}
+
+	return page.TotalCount, nil
+}
+
+// GetAllMarketItems walks every page of the market search for appid.
+// perPage defaults to (and is capped at) 100, Steam's maximum, and delay
+// is waited between two requests to stay below the rate limit.
+func (s *Session) GetAllMarketItems(appid, perPage uint64, delay time.Duration) ([]MarketItem, error) {
+	if perPage == 0 || perPage > maxMarketSearchPageSize {
+		perPage = maxMarketSearchPageSize
+	}
+
+	items := []MarketItem{}
+	for start := uint64(0); ; {
+		page, err := s.GetMarketItems(appid, start, perPage)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page.MarketItem...)
+		start += uint64(len(page.MarketItem))
+
+		if len(page.MarketItem) == 0 || start >= uint64(page.TotalCount) {
+			break
+		}
+
+		time.Sleep(delay)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,110 @@
+package steam
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestCleanPrice(t *testing.T) {
+	tests := []struct {
+		price      string
+		amount     string
+		symbol     string
+		currencyID string
+	}{
+		{"R$ 1,23", "1,23", "R$", "7"},
+		{"R$ 1.234,56", "1.234,56", "R$", "7"},
+		{"Rp 15 000", "15000", "Rp", "10"},
+		{"RM1.23", "1.23", "RM", "11"},
+		{"R 12.34", "12.34", "R", "28"},
+		{"12,34 kr", "12,34", "kr", "9"},
+		{"1 234,56 kr", "1234,56", "kr", "9"},
+		{"CHF 1.23", "1.23", "CHF", "4"},
+		{"$1.23", "1.23", "$", "1"},
+		{"1,23€", "1,23", "€", "3"},
+		{"--", "", "--", ""},
+	}
+
+	for _, tt := range tests {
+		amount, symbol, currencyID := cleanPrice(tt.price)
+		if amount != tt.amount || symbol != tt.symbol || currencyID != tt.currencyID {
+			t.Errorf("cleanPrice(%q) = %q, %q, %q, want %q, %q, %q",
+				tt.price, amount, symbol, currencyID, tt.amount, tt.symbol, tt.currencyID)
+		}
+	}
+}
+
+func TestMatchCurrencySymbol(t *testing.T) {
+	tests := []struct {
+		text       string
+		suffix     bool
+		symbol     string
+		currencyID string
+	}{
+		{"R$", false, "R$", "7"},
+		{"Rp", false, "Rp", "10"},
+		{"RM", false, "RM", "11"},
+		{"R", false, "R", "28"},
+		{"kr", true, "kr", "9"},
+		{"CHF", false, "CHF", "4"},
+	}
+
+	for _, tt := range tests {
+		symbol, currencyID := matchCurrencySymbol(tt.text, tt.suffix)
+		if symbol != tt.symbol || currencyID != tt.currencyID {
+			t.Errorf("matchCurrencySymbol(%q, %v) = %q, %q, want %q, %q",
+				tt.text, tt.suffix, symbol, currencyID, tt.symbol, tt.currencyID)
+		}
+	}
+}
+
+func TestParsePriceCentsForCurrency(t *testing.T) {
+	tests := []struct {
+		price      string
+		currencyID string
+		want       int64
+	}{
+		{"$1,234.56", CurrencyUSD, 123456},
+		{"$0.03 USD", CurrencyUSD, 3},
+		{"1,23€", CurrencyEUR, 123},
+		{"1 234,5€", CurrencyEUR, 123450},
+		{"1,--€", CurrencyEUR, 100},
+		{"1 234,56 pуб.", CurrencyRUB, 123456},
+		{"R$ 1.234,56", CurrencyBRL, 123456},
+		{"¥ 1,234", CurrencyJPY, 123400},
+		{"CHF 1.23", CurrencyCHF, 123},
+		{"", CurrencyUSD, 0},
+		{"1,23€", "", 123}, // Unknown currency
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePriceCentsForCurrency(tt.price, tt.currencyID)
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePriceCentsForCurrency(%q, %q) = %d, %v, want %d", tt.price, tt.currencyID, got, err, tt.want)
+		}
+	}
+
+	if _, err := ParsePriceCentsForCurrency("$1.2.3", CurrencyUSD); err == nil {
+		t.Error("expected an error for $1.2.3")
+	}
+}
+
+func TestPriceOverviewParseError(t *testing.T) {
+	session := newStubSession(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"success": true, "lowest_price": "$1.2.3", "median_price": "$1.00"}`)
+	}))
+
+	overview, err := session.GetMarketItemPriceOverview(730, "", CurrencyUSD, "AK-47")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if overview.ParseError == nil {
+		t.Error("ParseError is nil for an invalid lowest price")
+	}
+
+	if _, err = session.GetLowestPrice(730, "AK-47", CurrencyUSD); err == nil {
+		t.Error("GetLowestPrice returned no error for an invalid lowest price")
+	}
+}
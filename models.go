@@ -1,5 +1,11 @@
 package steam
 
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
 type ListingItem struct {
 	Success           bool                                   `json:"success"`
 	PageSize          uint64                                 `json:"pagesize"`
@@ -13,6 +19,21 @@ type ListingItem struct {
 	BuyOrders         []Listing                              `json:"buy_orders"`
 }
 
+// FullAsset returns the asset of listing with its description, which Steam
+// sends in the Assets map rather than within the listing itself.
+func (li *ListingItem) FullAsset(listing *Listing) Asset {
+	contexts, ok := li.Assets[strconv.FormatUint(listing.Asset.AppID, 10)]
+	if !ok {
+		return listing.Asset
+	}
+
+	if asset, ok := contexts[listing.Asset.ContextID][listing.Asset.ID]; ok {
+		return asset
+	}
+
+	return listing.Asset
+}
+
 type Asset struct {
 	Currency                    uint64        `json:"currency"`
 	AppID                       uint64        `json:"appid"`
@@ -45,6 +66,38 @@ type Asset struct {
 	Owner                       uint64        `json:"owner"`
 }
 
+// IconURLFull returns the full URL of the asset icon, see EconomyImageURL.
+func (asset *Asset) IconURLFull(size string) string {
+	return economyImageURL(asset.IconURL, size)
+}
+
+// Listing statuses as found in Listing.Status, these are the values the
+// mylistings page reports for each of its groups, others are reported as unknown.
+const (
+	ListingStatusActive            = 2
+	ListingStatusNeedsConfirmation = 17
+	ListingStatusOnHold            = 23
+)
+
+var listingStatusNames = map[uint64]string{
+	ListingStatusActive:            "active",
+	ListingStatusNeedsConfirmation: "needs confirmation",
+	ListingStatusOnHold:            "on hold",
+}
+
+// Asset statuses as found in Asset.Status, the asset of a listing is held
+// by the market until the listing is sold or removed.
+const (
+	AssetStatusNone      = 0
+	AssetStatusInListing = 2
+)
+
+// Listing cancel reasons as found in Listing.CancelReason, Steam reports
+// any other value for a listing it removed itself.
+const (
+	ListingCancelReasonNone = 0
+)
+
 type Listing struct {
 	ListingID                    string `json:"listingid"`
 	TimeCreated                  uint64 `json:"time_created"`
@@ -80,6 +133,133 @@ type Listing struct {
 	TimeCreatedStr               string `json:"time_created_str"`
 }
 
+var (
+	listingNumberFields = []string{
+		"time_created", "price", "original_price", "fee", "converted_price", "converted_fee",
+		"status", "active", "steam_fee", "converted_steam_fee", "publisher_fee", "converted_publisher_fee",
+		"publisher_fee_app", "cancel_reason", "item_expired", "original_amount_listed",
+		"original_price_per_unit", "fee_per_unit", "steam_fee_per_unit", "publisher_fee_per_unit",
+		"converted_price_per_unit", "converted_fee_per_unit", "converted_steam_fee_per_unit",
+		"converted_publisher_fee_per_unit", "time_finish_hold",
+	}
+	listingStringFields = []string{
+		"listingid", "steamid_lister", "currencyid", "converted_currencyid", "publisher_fee_percent",
+	}
+)
+
+// UnmarshalJSON accepts numbers sent as strings, and IDs sent as numbers,
+// since Steam is not consistent about either.
+func (listing *Listing) UnmarshalJSON(data []byte) error {
+	data, err := normalizeJSONFields(data, listingNumberFields, listingStringFields)
+	if err != nil {
+		return err
+	}
+
+	type plain Listing
+	return json.Unmarshal(data, (*plain)(listing))
+}
+
+// normalizeJSONFields rewrites the object in data so the numbers fields hold bare
+// numbers and the texts fields strings, whichever form Steam sent them in.
+// Empty strings in numbers fields are dropped, leaving zero.
+func normalizeJSONFields(data []byte, numbers, texts []string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		/* Not an object, let the caller's decoding report it.  */
+		return data, nil
+	}
+
+	changed := false
+	for _, key := range numbers {
+		raw, ok := fields[key]
+		if !ok || len(raw) == 0 || raw[0] != '"' {
+			continue
+		}
+
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case text == "":
+			delete(fields, key)
+		case json.Valid([]byte(text)):
+			fields[key] = json.RawMessage(text)
+		default:
+			continue
+		}
+		changed = true
+	}
+
+	for _, key := range texts {
+		raw, ok := fields[key]
+		if !ok || len(raw) == 0 || (raw[0] != '-' && (raw[0] < '0' || raw[0] > '9')) {
+			continue
+		}
+
+		fields[key] = json.RawMessage(strconv.Quote(string(bytes.TrimSpace(raw))))
+		changed = true
+	}
+
+	if !changed {
+		return data, nil
+	}
+
+	return json.Marshal(fields)
+}
+
+// Canceled reports whether Steam gave a reason for removing the listing.
+func (listing *Listing) Canceled() bool {
+	return listing.CancelReason != ListingCancelReasonNone
+}
+
+// StatusString returns a readable form of Status, e.g. "active".
+func (listing *Listing) StatusString() string {
+	if name, ok := listingStatusNames[listing.Status]; ok {
+		return name
+	}
+
+	return "unknown (" + strconv.FormatUint(listing.Status, 10) + ")"
+}
+
+// TotalFees returns the Steam and publisher fees of the listing in cents of CurrencyID.
+func (listing *Listing) TotalFees() int64 {
+	if listing.Fee != 0 {
+		return int64(listing.Fee)
+	}
+
+	return int64(listing.SteamFee + listing.PublisherFee)
+}
+
+// NetProceeds returns what the seller receives in cents of CurrencyID, Price
+// already excludes the fees the buyer pays on top of it.
+func (listing *Listing) NetProceeds() int64 {
+	return int64(listing.Price)
+}
+
+// BuyerPays returns the price including fees in cents of CurrencyID.
+func (listing *Listing) BuyerPays() int64 {
+	return listing.NetProceeds() + listing.TotalFees()
+}
+
+// IsActive reports whether the listing can be bought.
+func (listing *Listing) IsActive() bool {
+	return listing.Active == 1
+}
+
+// BuyOrder is an active buy order, Price is the price per item in cents of WalletCurrency.
+type BuyOrder struct {
+	OrderID           uint64           `json:"buy_orderid,string"`
+	AppID             uint64           `json:"appid"`
+	HashName          string           `json:"hash_name"`
+	WalletCurrency    uint64           `json:"wallet_currency"`
+	Price             uint64           `json:"price,string"`
+	Quantity          uint64           `json:"quantity,string"`
+	QuantityRemaining uint64           `json:"quantity_remaining,string"`
+	Description       AssetDescription `json:"description"`
+}
+
 type Description struct {
 	Type  string `json:"type"`
 	Value string `json:"value"`
@@ -141,6 +321,11 @@ type AssetDescription struct {
 	Marketable                  uint64 `json:"marketable"`
 }
 
+// IconURLFull returns the full URL of the described icon, see EconomyImageURL.
+func (desc *AssetDescription) IconURLFull(size string) string {
+	return economyImageURL(desc.IconURL, size)
+}
+
 type MarketItem struct {
 	Name             string           `json:"name"`
 	HashName         string           `json:"hash_name"`
@@ -153,6 +338,27 @@ type MarketItem struct {
 	SalePriceText    string           `json:"sale_price_text"`
 }
 
+// LowestPriceCents returns the starting price shown in SellPriceText in cents,
+// or SellPrice when the text cannot be parsed.
+func (item *MarketItem) LowestPriceCents() int64 {
+	if cents, err := ParsePriceCents(item.SellPriceText); err == nil && cents != 0 {
+		return cents
+	}
+
+	return int64(item.SellPrice)
+}
+
+// UnmarshalJSON accepts the counts and price sent either as numbers or as strings.
+func (item *MarketItem) UnmarshalJSON(data []byte) error {
+	data, err := normalizeJSONFields(data, []string{"sell_listings", "sell_price"}, nil)
+	if err != nil {
+		return err
+	}
+
+	type plain MarketItem
+	return json.Unmarshal(data, (*plain)(item))
+}
+
 type SteamMarketItems struct {
 	Success    bool         `json:"success"`
 	Start      int          `json:"start"`
@@ -162,6 +368,16 @@ type SteamMarketItems struct {
 	MarketItem []MarketItem `json:"results"`
 }
 
+// ByHashName returns the results keyed by market hash name.
+func (items *SteamMarketItems) ByHashName() map[string]MarketItem {
+	byName := make(map[string]MarketItem, len(items.MarketItem))
+	for _, item := range items.MarketItem {
+		byName[item.HashName] = item
+	}
+
+	return byName
+}
+
 type SteamTimeResponse struct {
 	SteamTime *SteamTime `json:"response"`
 }
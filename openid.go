@@ -29,7 +29,7 @@ func (session *Session) Auth(realm, return_to string) (*http.Response, error) {
 	req.Header.Add("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/51.0.2704.103 Safari/537.36")
 	req.Header.Add("Accept", "*/*")
 
-	resp, err := session.client.Do(req)
+	resp, err := session.httpClient().Do(req)
 
 	if err != nil {
 		return nil, err
@@ -73,5 +73,5 @@ func (session *Session) Auth(realm, return_to string) (*http.Response, error) {
 	req.Header.Add("Accept-Language", "en-US,en;q=0.5")
 	req.Header.Add("Accept-Encoding", "gzip, deflate, br")
 
-	return session.client.Do(req)
+	return session.httpClient().Do(req)
 }
@@ -0,0 +1,84 @@
+package steam
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"time"
+)
+
+// defaultClientTimeout bounds the requests of the client sessions get when none is given,
+// it leaves room for long polls such as ChatPoll.
+const defaultClientTimeout = 60 * time.Second
+
+// SessionOption configures a Session created by NewSession or NewSessionWithOptions.
+type SessionOption func(*Session)
+
+// WithHTTPClient sets the client every request is sent with, e.g. one configured with a proxy.
+// A nil client keeps the default one.
+func WithHTTPClient(client *http.Client) SessionOption {
+	return func(session *Session) {
+		if client != nil {
+			session.client = client
+		}
+	}
+}
+
+// WithAPIKey sets the Web API key.
+func WithAPIKey(apiKey string) SessionOption {
+	return func(session *Session) {
+		session.apiKey = apiKey
+	}
+}
+
+// WithLanguage sets the language of descriptions and market data, it defaults to "english".
+// Requests also ask for it with the Accept-Language header.
+func WithLanguage(lang string) SessionOption {
+	return func(session *Session) {
+		session.language = lang
+	}
+}
+
+// WithDeviceID sets the mobile device ID used for confirmations, which is otherwise
+// derived from the credentials on login. See GenerateDeviceID.
+func WithDeviceID(deviceID string) SessionOption {
+	return func(session *Session) {
+		session.deviceID = deviceID
+	}
+}
+
+// NewSessionWithOptions creates a session with a default client and the "english" language,
+// as modified by opts.  The default client has a cookie jar and a timeout of one minute.
+// It is the options-only form of NewSession, whose signature is kept for compatibility.
+func NewSessionWithOptions(opts ...SessionOption) *Session {
+	session := &Session{
+		client:   newDefaultClient(),
+		language: "english",
+	}
+
+	for _, opt := range opts {
+		opt(session)
+	}
+
+	return session
+}
+
+func newDefaultClient() *http.Client {
+	/* cookiejar.New never fails without options.  */
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{Jar: jar, Timeout: defaultClientTimeout}
+}
+
+// httpClient returns the client of the session, a Session not created by one of
+// the constructors gets a default client on first use.  Either way, the client's
+// transport is wrapped by the session's on first use.
+func (session *Session) httpClient() *http.Client {
+	session.clientOnce.Do(func() {
+		if session.client == nil {
+			session.client = newDefaultClient()
+		}
+
+		session.wrapClient(session.client)
+	})
+
+	return session.client
+}
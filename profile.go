@@ -1,12 +1,14 @@
 package steam
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -81,8 +83,46 @@ type Friend struct {
 	FriendSince  int64  `json:"friend_since"`
 }
 
+// GetProfileURL returns the URL of the logged in profile, it is resolved once
+// and then cached, see RefreshProfileURL.
 func (session *Session) GetProfileURL() (string, error) {
-	tmpClient := http.Client{Jar: session.client.Jar}
+	session.profileMu.Lock()
+	profileURL := session.profileURL
+	session.profileMu.Unlock()
+
+	if profileURL != "" {
+		return profileURL, nil
+	}
+
+	return session.RefreshProfileURL()
+}
+
+// RefreshProfileURL resolves the URL of the logged in profile again, e.g. after a custom URL change.
+func (session *Session) RefreshProfileURL() (string, error) {
+	profileURL, err := session.fetchProfileURL()
+	if err != nil {
+		return "", err
+	}
+
+	/* Logged out sessions are sent to the login page, which is not worth keeping.  */
+	if !strings.Contains(profileURL, "/login") {
+		session.profileMu.Lock()
+		session.profileURL = profileURL
+		session.profileMu.Unlock()
+	}
+
+	return profileURL, nil
+}
+
+func (session *Session) resetProfileURL() {
+	session.profileMu.Lock()
+	session.profileURL = ""
+	session.profileMu.Unlock()
+}
+
+func (session *Session) fetchProfileURL() (string, error) {
+	client := session.httpClient()
+	tmpClient := http.Client{Jar: client.Jar, Transport: client.Transport}
 
 	/* We do not follow redirect, we want to know where it'd redirect us.  */
 	tmpClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
@@ -105,7 +145,7 @@ func (session *Session) GetProfileURL() (string, error) {
 }
 
 func (session *Session) SetupProfile(profileURL string) error {
-	resp, err := session.client.Get(profileURL + "/edit?welcomed=1")
+	resp, err := session.httpClient().Get(profileURL + "/edit?welcomed=1")
 	if resp != nil {
 		resp.Body.Close()
 	}
@@ -125,7 +165,7 @@ func (session *Session) SetProfileInfo(profileURL string, values *map[string][]s
 	(*values)["sessionID"] = []string{session.sessionID}
 	(*values)["type"] = []string{"profileSave"}
 
-	resp, err := session.client.PostForm(profileURL+"/edit", *values)
+	resp, err := session.httpClient().PostForm(profileURL+"/edit", *values)
 	if resp != nil {
 		resp.Body.Close()
 	}
@@ -142,7 +182,7 @@ func (session *Session) SetProfileInfo(profileURL string, values *map[string][]s
 }
 
 func (session *Session) SetProfilePrivacy(profileURL string, commentPrivacy string, privacy uint8) error {
-	resp, err := session.client.PostForm(profileURL+"/edit/settings", url.Values{
+	resp, err := session.httpClient().PostForm(profileURL+"/edit/settings", url.Values{
 		"sessionID":               {session.sessionID},
 		"type":                    {"profileSettings"},
 		"commentSetting":          {commentPrivacy},
@@ -166,7 +206,7 @@ func (session *Session) SetProfilePrivacy(profileURL string, commentPrivacy stri
 }
 
 func (session *Session) GetPlayerSummaries(steamids string) ([]*PlayerSummary, error) {
-	resp, err := session.client.Get(apiGetPlayerSummaries + url.Values{
+	resp, err := session.httpClient().Get(apiGetPlayerSummaries + url.Values{
 		"key":      {session.apiKey},
 		"steamids": {steamids},
 	}.Encode())
@@ -195,7 +235,7 @@ func (session *Session) GetPlayerSummaries(steamids string) ([]*PlayerSummary, e
 }
 
 func (session *Session) GetOwnedGames(sid SteamID, freeGames bool, appInfo bool) (*OwnedGamesResponse, error) {
-	resp, err := session.client.Get(apiGetOwnedGames + url.Values{
+	resp, err := session.httpClient().Get(apiGetOwnedGames + url.Values{
 		"key":                       {session.apiKey},
 		"steamid":                   {sid.ToString()},
 		"format":                    {"json"},
@@ -223,7 +263,7 @@ func (session *Session) GetOwnedGames(sid SteamID, freeGames bool, appInfo bool)
 }
 
 func (session *Session) GetPlayerBans(steamids string) ([]*PlayerBan, error) {
-	resp, err := session.client.Get(apiGetPlayerBans + url.Values{
+	resp, err := session.httpClient().Get(apiGetPlayerBans + url.Values{
 		"key":      {session.apiKey},
 		"steamids": {steamids},
 	}.Encode())
@@ -248,7 +288,7 @@ func (session *Session) GetPlayerBans(steamids string) ([]*PlayerBan, error) {
 }
 
 func (session *Session) GetFriends(sid SteamID) ([]*Friend, error) {
-	resp, err := session.client.Get(apiGetPlayerFriends + url.Values{
+	resp, err := session.httpClient().Get(apiGetPlayerFriends + url.Values{
 		"key":     {session.apiKey},
 		"steamid": {sid.ToString()},
 		"format":  {"json"},
@@ -277,8 +317,48 @@ func (session *Session) GetFriends(sid SteamID) ([]*Friend, error) {
 	return friendsList.Inner.Friends, nil
 }
 
+// ResolveFriendList returns the SteamIDs of the friends of sid through ISteamUser/GetFriendList,
+// using apiKey or the session's key when empty.  A friend list sid keeps private
+// is reported as ErrProfilePrivate.
+func (session *Session) ResolveFriendList(apiKey string, sid SteamID) ([]SteamID, error) {
+	if apiKey == "" {
+		apiKey = session.apiKey
+	}
+
+	if apiKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	type Response struct {
+		Inner struct {
+			Friends []*Friend `json:"friends"`
+		} `json:"friendslist"`
+	}
+
+	var response Response
+	if err := session.getJSON(context.Background(), http.MethodGet, apiGetPlayerFriends+url.Values{
+		"key":          {apiKey},
+		"steamid":      {sid.ToString()},
+		"relationship": {"friend"},
+	}.Encode(), nil, "", &response); err != nil {
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusUnauthorized {
+			return nil, ErrProfilePrivate
+		}
+
+		return nil, err
+	}
+
+	friends := make([]SteamID, len(response.Inner.Friends))
+	for i, friend := range response.Inner.Friends {
+		friends[i] = SteamID(friend.SteamID)
+	}
+
+	return friends, nil
+}
+
 func (session *Session) ResolveVanityURL(vanityURL string) (uint64, error) {
-	resp, err := session.client.Get(apiResolveVanityURL + url.Values{
+	resp, err := session.httpClient().Get(apiResolveVanityURL + url.Values{
 		"key":       {session.apiKey},
 		"vanityurl": {vanityURL},
 	}.Encode())
@@ -310,3 +390,29 @@ func (session *Session) ResolveVanityURL(vanityURL string) (uint64, error) {
 
 	return response.Inner.SteamID, nil
 }
+
+// ResolveSteamID is like ParseSteamID, but also accepts vanity names and URLs
+// such as "gabelogannewell" or "https://steamcommunity.com/id/gabelogannewell/".
+func (session *Session) ResolveSteamID(input string) (SteamID, error) {
+	sid, err := ParseSteamID(input)
+	if err == nil {
+		return sid, nil
+	}
+
+	vanity := strings.TrimSpace(input)
+	if i := strings.Index(vanity, "/id/"); i != -1 {
+		vanity = vanity[i+len("/id/"):]
+	}
+	vanity = strings.Trim(vanity, "/")
+
+	if vanity == "" || strings.ContainsAny(vanity, "/[:") {
+		return 0, err
+	}
+
+	id, err := session.ResolveVanityURL(vanity)
+	if err != nil {
+		return 0, err
+	}
+
+	return SteamID(id), nil
+}
@@ -0,0 +1,106 @@
+package steam
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles outgoing requests, Wait blocks until a request may be sent.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a RateLimiter allowing rate requests per second on average
+// with bursts of up to burst requests.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// minTokenBucketRate is the rate of token buckets created with a rate that is not positive.
+const minTokenBucketRate = 1.0 / 60
+
+// NewTokenBucket creates a TokenBucket starting full.  A rate that is not positive
+// is raised to one request per minute and a burst below 1 to 1, so that it keeps limiting.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if !(rate > 0) {
+		rate = minTokenBucketRate
+	}
+
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+
+	/* Take the token right away, waiting for it to be refilled if it is owed.  */
+	b.tokens--
+	if b.tokens >= 0 {
+		b.mu.Unlock()
+		return nil
+	}
+
+	delay := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// SetRateLimiter throttles the requests sent to host (e.g. "steamcommunity.com") with limiter.
+// An empty host sets the limiter of hosts without their own, a nil limiter removes it.
+func (session *Session) SetRateLimiter(host string, limiter RateLimiter) {
+	session.transportMu.Lock()
+	defer session.transportMu.Unlock()
+
+	if limiter == nil {
+		delete(session.limiters, host)
+	} else {
+		if session.limiters == nil {
+			session.limiters = make(map[string]RateLimiter)
+		}
+		session.limiters[host] = limiter
+	}
+
+	session.installTransport()
+}
+
+func (session *Session) rateLimiter(host string) RateLimiter {
+	session.transportMu.Lock()
+	defer session.transportMu.Unlock()
+
+	if limiter, ok := session.limiters[host]; ok {
+		return limiter
+	}
+
+	return session.limiters[""]
+}
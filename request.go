@@ -0,0 +1,254 @@
+package steam
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrNotLoggedIn is returned when Steam sends a request to its login page, the session has to log in again.
+	ErrNotLoggedIn = errors.New("not logged in")
+
+	// ErrRateLimited matches the errors of requests Steam refused because of their rate,
+	// either with HTTP 429 or, for inventories, with a response lacking any data.
+	ErrRateLimited = errors.New("rate limited by Steam")
+)
+
+// HTTPError is returned when Steam answers with a status other than 200 OK.
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("http error: %d", e.StatusCode)
+}
+
+// Is makes errors.Is(err, ErrRateLimited) hold for HTTP 429.
+func (e *HTTPError) Is(target error) bool {
+	return target == ErrRateLimited && e.StatusCode == http.StatusTooManyRequests
+}
+
+// doRequest sends a request through the session's client, form is sent url-encoded when not nil.
+// Any status but 200 OK is returned as an *HTTPError, otherwise the caller must close the body.
+// Requests are retried as configured by SetRetryPolicy.
+func (session *Session) doRequest(ctx context.Context, method, endpoint string, form url.Values, referer string) (*http.Response, error) {
+	var resp *http.Response
+	err := retryWithBackoff(ctx, session.retryPolicy, func() error {
+		var body io.Reader
+		if form != nil {
+			body = strings.NewReader(form.Encode())
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+		if err != nil {
+			return err
+		}
+
+		if form != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+		}
+
+		if referer != "" {
+			req.Header.Set("Referer", referer)
+		}
+
+		resp, err = session.httpClient().Do(req)
+		if err != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			resp = nil
+			return err
+		}
+
+		if isLoginRedirect(resp) {
+			resp.Body.Close()
+			resp = nil
+			return ErrNotLoggedIn
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests {
+			resp.Body.Close()
+			err := session.rateLimited(endpoint, resp)
+			resp = nil
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			err := error(&HTTPError{StatusCode: resp.StatusCode})
+			if isRetryableStatus(method, resp.StatusCode) {
+				err = &retryableError{err, retryAfter(resp)}
+			}
+			resp = nil
+			return err
+		}
+
+		if err = decompressBody(resp); err != nil {
+			resp.Body.Close()
+			resp = nil
+			return err
+		}
+
+		return nil
+	})
+
+	return resp, err
+}
+
+// steamLanguageTags maps Steam's language names to the tags of Accept-Language.
+var steamLanguageTags = map[string]string{
+	"arabic":     "ar",
+	"brazilian":  "pt-BR",
+	"bulgarian":  "bg",
+	"czech":      "cs",
+	"danish":     "da",
+	"dutch":      "nl",
+	"english":    "en",
+	"finnish":    "fi",
+	"french":     "fr",
+	"german":     "de",
+	"greek":      "el",
+	"hungarian":  "hu",
+	"indonesian": "id",
+	"italian":    "it",
+	"japanese":   "ja",
+	"koreana":    "ko",
+	"latam":      "es-419",
+	"norwegian":  "no",
+	"polish":     "pl",
+	"portuguese": "pt",
+	"romanian":   "ro",
+	"russian":    "ru",
+	"schinese":   "zh-CN",
+	"spanish":    "es",
+	"swedish":    "sv",
+	"tchinese":   "zh-TW",
+	"thai":       "th",
+	"turkish":    "tr",
+	"ukrainian":  "uk",
+	"vietnamese": "vi",
+}
+
+// acceptLanguage returns the Accept-Language header matching the Steam language lang,
+// empty for unknown languages.
+func acceptLanguage(lang string) string {
+	tag, ok := steamLanguageTags[lang]
+	if !ok {
+		return ""
+	}
+
+	if tag == "en" {
+		return tag
+	}
+
+	return tag + ",en;q=0.8"
+}
+
+// isLoginRedirect reports whether Steam sent resp, or redirected the request, to its login page,
+// which it does for requests of logged out sessions.
+func isLoginRedirect(resp *http.Response) bool {
+	location := resp.Request.URL
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		if l, err := resp.Location(); err == nil {
+			location = l
+		}
+	}
+
+	return location.Host == "login.steampowered.com" ||
+		(strings.HasSuffix(location.Host, "steamcommunity.com") && strings.HasPrefix(location.Path, "/login"))
+}
+
+// isRetryableStatus reports whether a request can safely be sent again after a response with status.
+// Server errors may come after side effects, so only requests without any are retried then.
+func isRetryableStatus(method string, status int) bool {
+	switch status {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return method == http.MethodGet
+	}
+
+	return false
+}
+
+// decompressBody replaces the body of resp with its decompressed form.
+// The transport only does so itself for requests it set Accept-Encoding on,
+// not when the header comes from the caller, e.g. through SetInventoryHeaders.
+func decompressBody(resp *http.Response) error {
+	var reader io.ReadCloser
+	var err error
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		reader, err = gzip.NewReader(resp.Body)
+	case "deflate":
+		reader, err = zlib.NewReader(resp.Body)
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to decompress response: %w", err)
+	}
+
+	resp.Body = &decompressedBody{reader, resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+
+	return nil
+}
+
+type decompressedBody struct {
+	io.ReadCloser
+	raw io.ReadCloser
+}
+
+func (body *decompressedBody) Close() error {
+	body.ReadCloser.Close()
+	return body.raw.Close()
+}
+
+// getJSON sends a request via doRequest and decodes the JSON response into out.
+func (session *Session) getJSON(ctx context.Context, method, endpoint string, form url.Values, referer string, out interface{}) error {
+	resp, err := session.doRequest(ctx, method, endpoint, form, referer)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewDecoder(bytes.NewReader(body)).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w (body: %s)", err, bodySnippet(body))
+	}
+
+	return nil
+}
+
+// maxBodySnippet is how much of a body undecodable responses show in errors.
+const maxBodySnippet = 256
+
+// bodySnippet returns the start of body for error messages.
+func bodySnippet(body []byte) string {
+	body = bytes.TrimSpace(body)
+	if len(body) <= maxBodySnippet {
+		return strconv.Quote(string(body))
+	}
+
+	return strconv.Quote(string(body[:maxBodySnippet])) + "..."
+}
@@ -0,0 +1,134 @@
+package steam
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// newCompressedServer answers every request with body compressed as encoding,
+// whatever the request asked for.
+func newCompressedServer(t *testing.T, encoding string, body []byte) *httptest.Server {
+	t.Helper()
+
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "deflate":
+		w = zlib.NewWriter(&buf)
+	default:
+		t.Fatalf("unknown encoding %q", encoding)
+	}
+
+	if _, err := w.Write(body); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	compressed := buf.Bytes()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(compressed)
+	}))
+}
+
+// redirectTransport sends every request to the test server, whatever its host,
+// so that the session's requests to Steam can be answered by a handler.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newStubSession returns a session whose requests are all answered by handler.
+func newStubSession(t *testing.T, handler http.Handler) *Session {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewSession(&http.Client{Transport: &redirectTransport{target}}, "")
+}
+
+// newUncompressingSession returns a session whose transport leaves compressed
+// responses as they are, as it does when the caller sets Accept-Encoding.
+func newUncompressingSession() *Session {
+	return NewSession(&http.Client{Transport: &http.Transport{DisableCompression: true}}, "")
+}
+
+func TestGetJSONDecompresses(t *testing.T) {
+	for _, encoding := range []string{"gzip", "deflate"} {
+		srv := newCompressedServer(t, encoding, []byte(`{"success":true,"name":"AK-47"}`))
+
+		var out struct {
+			Success bool   `json:"success"`
+			Name    string `json:"name"`
+		}
+
+		session := newUncompressingSession()
+		if err := session.getJSON(context.Background(), http.MethodGet, srv.URL, nil, "", &out); err != nil {
+			t.Errorf("%s: getJSON: %v", encoding, err)
+		} else if !out.Success || out.Name != "AK-47" {
+			t.Errorf("%s: getJSON decoded %+v", encoding, out)
+		}
+
+		srv.Close()
+	}
+}
+
+func TestGetWithRetryDecompresses(t *testing.T) {
+	srv := newCompressedServer(t, "gzip", []byte(`{"success":1}`))
+	defer srv.Close()
+
+	session := newUncompressingSession()
+	resp, err := session.getWithRetry(context.Background(), srv.URL, http.Header{"Accept-Encoding": {"gzip"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(body) != `{"success":1}` {
+		t.Errorf("body = %q", body)
+	}
+
+	if resp.Header.Get("Content-Encoding") != "" {
+		t.Errorf("Content-Encoding = %q, want it removed", resp.Header.Get("Content-Encoding"))
+	}
+}
+
+func TestDecompressBodyInvalid(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{"Content-Encoding": {"gzip"}},
+		Body:   io.NopCloser(bytes.NewReader([]byte("not gzip"))),
+	}
+
+	if err := decompressBody(resp); err == nil {
+		t.Error("expected an error for a body that is not gzip")
+	}
+}
@@ -0,0 +1,166 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how requests Steam rejected temporarily (HTTP 429, or a 5xx
+// for requests without side effects) are retried.  The zero value disables retries.
+type RetryPolicy struct {
+	MaxAttempts int           // Including the first one
+	BaseDelay   time.Duration // Defaults to one second
+	MaxDelay    time.Duration // No limit when zero
+	Multiplier  float64       // Defaults to 2
+	Jitter      float64       // Fraction of the delay that is randomized, from 0 to 1
+}
+
+// delay returns how long to wait after the given failed attempt, counting from 0.
+func (policy *RetryPolicy) delay(attempt int) time.Duration {
+	base := policy.BaseDelay
+	if base <= 0 {
+		base = defaultBaseBackoff
+	}
+
+	multiplier := policy.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	delay := float64(base) * math.Pow(multiplier, float64(attempt))
+	if policy.MaxDelay > 0 && delay > float64(policy.MaxDelay) {
+		delay = float64(policy.MaxDelay)
+	}
+
+	/* The largest float below 2^63, past it converting to a Duration overflows.  */
+	if maxDelay := math.Nextafter(math.MaxInt64, 0); delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if jitter := math.Min(policy.Jitter, 1); jitter > 0 {
+		delay -= delay * jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+// retryableError marks an error worth another attempt, after is the delay
+// Steam asked for (Retry-After), if any.
+type retryableError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// retryAfter returns the delay Steam asked for in the Retry-After header, or zero.
+func retryAfter(resp *http.Response) time.Duration {
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	return 0
+}
+
+// retryWithBackoff calls fn until it succeeds, fails with an error not marked
+// retryable, the policy runs out of attempts or ctx is done.
+func retryWithBackoff(ctx context.Context, policy RetryPolicy, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+
+		var retryable *retryableError
+		if !errors.As(err, &retryable) {
+			return err
+		}
+
+		if attempt+1 >= policy.MaxAttempts {
+			return retryable.err
+		}
+
+		delay := retryable.after
+		if delay <= 0 {
+			delay = policy.delay(attempt)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// isTransient reports whether err may go away by trying again later: network failures,
+// HTTP 429 and 5xx answers, and throttled responses.
+func isTransient(err error) bool {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// sleepContext waits for d, or returns ctx.Err() as soon as ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// SetRetryPolicy sets how rate-limited and temporarily failing requests are retried.
+func (session *Session) SetRetryPolicy(policy RetryPolicy) {
+	session.retryPolicy = policy
+}
+
+// RateLimitFunc is called whenever Steam rate limits a request to endpoint,
+// retryAfter is the delay it asked for, zero if none.
+type RateLimitFunc func(endpoint string, retryAfter time.Duration)
+
+// SetOnRateLimit registers fn to be called on every rate limited request, e.g. to slow
+// all of a bot's sessions down.  A nil fn removes it.
+func (session *Session) SetOnRateLimit(fn RateLimitFunc) {
+	session.onRateLimit = fn
+}
+
+// rateLimited reports a rate limited request to endpoint and returns the error to retry it with.
+func (session *Session) rateLimited(endpoint string, resp *http.Response) error {
+	after := retryAfter(resp)
+	if session.onRateLimit != nil {
+		session.onRateLimit(endpoint, after)
+	}
+
+	return &retryableError{&HTTPError{StatusCode: resp.StatusCode}, after}
+}
+
+// reportRateLimit reports a request to endpoint Steam throttled without saying so with a 429.
+func (session *Session) reportRateLimit(endpoint string) {
+	if session.onRateLimit != nil {
+		session.onRateLimit(endpoint, 0)
+	}
+}
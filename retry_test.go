@@ -0,0 +1,26 @@
+package steam
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second}
+	if got := policy.delay(2); got != 4*time.Second {
+		t.Errorf("delay(2) = %v, want 4s", got)
+	}
+
+	policy.MaxDelay = 3 * time.Second
+	if got := policy.delay(2); got != 3*time.Second {
+		t.Errorf("delay(2) with MaxDelay 3s = %v, want 3s", got)
+	}
+
+	/* Without MaxDelay, a large attempt must not overflow into a negative delay.  */
+	policy.MaxDelay = 0
+	for _, attempt := range []int{62, 100, 2000} {
+		if got := policy.delay(attempt); got <= 0 {
+			t.Errorf("delay(%d) = %v, want a positive delay", attempt, got)
+		}
+	}
+}
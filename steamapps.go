@@ -1,7 +1,8 @@
 package steam
 
 import (
-	"encoding/json"
+	"context"
+	"net/http"
 	"net/url"
 	"strconv"
 )
@@ -11,18 +12,6 @@ const (
 )
 
 func (session *Session) GetRequiredSteamAppVersion(appID int) (int, error) {
-	resp, err := session.client.Get(apiUpToDateCheck + url.Values{
-		"appid":   {strconv.Itoa(appID)},
-		"version": {"0"},
-	}.Encode())
-	if resp != nil {
-		defer resp.Body.Close()
-	}
-
-	if err != nil {
-		return 0, err
-	}
-
 	type UpToDateCheckResponse struct {
 		RequiredVersion int `json:"required_version"`
 	}
@@ -32,7 +21,10 @@ func (session *Session) GetRequiredSteamAppVersion(appID int) (int, error) {
 	}
 
 	var response Response
-	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if err := session.getJSON(context.Background(), http.MethodGet, apiUpToDateCheck+url.Values{
+		"appid":   {strconv.Itoa(appID)},
+		"version": {"0"},
+	}.Encode(), nil, "", &response); err != nil {
 		return 0, err
 	}
 	return response.Inner.RequiredVersion, nil
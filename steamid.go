@@ -3,8 +3,10 @@ package steam
 import (
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -60,6 +62,7 @@ var (
 
 	ErrInvalidSteam2ID = errors.New("invalid input specified for a Steam 2 ID")
 	ErrInvalidSteam3ID = errors.New("invalid input specified for a Steam 3 ID")
+	ErrInvalidSteamID  = errors.New("invalid input specified for a Steam ID")
 )
 
 /*
@@ -107,8 +110,8 @@ func (sid *SteamID) ParseSteam3ID(input string) error {
 	universe, _ := strconv.ParseUint(string(m[2]), 10, 8)
 
 	instance := uint64(AccountInstanceDesktop)
-	if len(m) > 5 {
-		instance, _ = strconv.ParseUint(string(m[5]), 10, 32)
+	if m[4] != "" {
+		instance, _ = strconv.ParseUint(m[4][1:], 10, 32)
 	}
 
 	accountType := uint32(AccountTypeIndividual)
@@ -143,6 +146,41 @@ func (sid *SteamID) ParseSteam3ID(input string) error {
 	return nil
 }
 
+// ParseSteamID parses a SteamID64 ("76561197960287930"), an account ID ("22202"),
+// a Steam 2 ID ("STEAM_0:0:11101"), a Steam 3 ID ("[U:1:22202]") or a profile URL
+// holding one of them. Vanity URLs need a request, see Session.ResolveSteamID.
+func ParseSteamID(input string) (SteamID, error) {
+	input = strings.TrimSpace(input)
+	if i := strings.Index(input, "/profiles/"); i != -1 {
+		input = strings.Trim(input[i+len("/profiles/"):], "/")
+	}
+
+	var sid SteamID
+	switch {
+	case strings.HasPrefix(input, "STEAM_"):
+		if err := sid.ParseSteam2ID(input); err != nil {
+			return 0, err
+		}
+	case strings.HasPrefix(input, "["):
+		if err := sid.ParseSteam3ID(input); err != nil {
+			return 0, err
+		}
+	default:
+		id, err := strconv.ParseUint(input, 10, 64)
+		if err != nil || id == 0 {
+			return 0, ErrInvalidSteamID
+		}
+
+		if id <= math.MaxUint32 {
+			sid.ParseDefaults(uint32(id))
+		} else {
+			sid = SteamID(id)
+		}
+	}
+
+	return sid, nil
+}
+
 func (sid *SteamID) GetAccountID() uint32 {
 	return uint32(*sid)
 }
@@ -163,6 +201,26 @@ func (sid *SteamID) ToString() string {
 	return strconv.FormatUint(uint64(*sid), 10)
 }
 
+// To32 returns the 32-bit account ID.
+func (sid *SteamID) To32() uint32 {
+	return sid.GetAccountID()
+}
+
+// To64 returns the SteamID64.
+func (sid *SteamID) To64() uint64 {
+	return uint64(*sid)
+}
+
+// ToSteamID2 returns the Steam 2 ID, e.g. "STEAM_0:0:11101".
+func (sid *SteamID) ToSteamID2() string {
+	return sid.ToSteam2ID()
+}
+
+// ToSteamID3 returns the Steam 3 ID, e.g. "[U:1:22202]".
+func (sid *SteamID) ToSteamID3() string {
+	return sid.ToSteam3ID()
+}
+
 func (sid *SteamID) ToSteam2ID() string {
 	universe := sid.GetAccountUniverse()
 	accountID := sid.GetAccountID()
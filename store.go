@@ -20,11 +20,11 @@ func (session *Session) PrepareForSteamStore() {
 	community, _ := url.Parse("https://steamcommunity.com")
 	store, _ := url.Parse("https://store.steampowered.com")
 
-	session.client.Jar.SetCookies(store, session.client.Jar.Cookies(community))
+	session.httpClient().Jar.SetCookies(store, session.httpClient().Jar.Cookies(community))
 }
 
 func (session *Session) ValidatePhoneNumber(number string) error {
-	resp, err := session.client.Get("https://store.steampowered.com/phone/validate?phoneNumber=" + url.QueryEscape(number))
+	resp, err := session.httpClient().Get("https://store.steampowered.com/phone/validate?phoneNumber=" + url.QueryEscape(number))
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -46,7 +46,7 @@ func (session *Session) ValidatePhoneNumber(number string) error {
 }
 
 func (session *Session) AddPhoneNumber(number string) error {
-	resp, err := session.client.Get("https://store.steampowered.com/phone/add_ajaxop?" + url.Values{
+	resp, err := session.httpClient().Get("https://store.steampowered.com/phone/add_ajaxop?" + url.Values{
 		"op":        {"get_phone_number"},
 		"input":     {number},
 		"sessionID": {session.sessionID},
@@ -73,7 +73,7 @@ func (session *Session) AddPhoneNumber(number string) error {
 }
 
 func (session *Session) InitiateRemovePhoneNumber() error {
-	resp, err := session.client.PostForm("https://store.steampowered.com/phone/remove_confirm_sms", url.Values{
+	resp, err := session.httpClient().PostForm("https://store.steampowered.com/phone/remove_confirm_sms", url.Values{
 		"sessionID": {session.sessionID},
 		"bWasEdit":  {""},
 	})
@@ -93,7 +93,7 @@ func (session *Session) InitiateRemovePhoneNumber() error {
 }
 
 func (session *Session) ConfirmRemovePhoneNumber(mobileCode string) error {
-	resp, err := session.client.PostForm("https://store.steampowered.com/phone/remove_confirm_smscode_entry", url.Values{
+	resp, err := session.httpClient().PostForm("https://store.steampowered.com/phone/remove_confirm_smscode_entry", url.Values{
 		"sessionID": {session.sessionID},
 		"bWasEdit":  {""},
 		"smscode":   {mobileCode},
@@ -115,7 +115,7 @@ func (session *Session) ConfirmRemovePhoneNumber(mobileCode string) error {
 }
 
 func (session *Session) ReSendVerificationCode() error {
-	resp, err := session.client.Get("https://store.steampowered.com/phone/add_ajaxop?" + url.Values{
+	resp, err := session.httpClient().Get("https://store.steampowered.com/phone/add_ajaxop?" + url.Values{
 		"op":        {"resend_sms"},
 		"input":     {""},
 		"sessionID": {session.sessionID},
@@ -146,7 +146,7 @@ func (session *Session) ReSendVerificationCode() error {
 }
 
 func (session *Session) VerifyPhoneNumber(code string) error {
-	resp, err := session.client.Get("https://store.steampowered.com/phone/add_ajaxop?" + url.Values{
+	resp, err := session.httpClient().Get("https://store.steampowered.com/phone/add_ajaxop?" + url.Values{
 		"op":        {"get_sms_code"},
 		"input":     {code},
 		"sessionID": {session.sessionID},
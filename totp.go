@@ -5,6 +5,7 @@ import (
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 )
@@ -26,14 +27,21 @@ type ServerTimeTip struct {
 	MaxAttempts                       uint32 `json:"max_attempts"`
 }
 
+// GenerateTwoFactorCode returns the Steam Guard login code for sharedSecret at the unix time current.
 func GenerateTwoFactorCode(sharedSecret string, current int64) (string, error) {
+	return GenerateTwoFactorCodeForTime(sharedSecret, current)
+}
+
+// GenerateTwoFactorCodeForTime implements Steam's TOTP variant: an HMAC-SHA1 over the
+// 30 seconds time step, truncated and spelled with a 26 characters alphabet into 5 characters.
+func GenerateTwoFactorCodeForTime(sharedSecret string, timestamp int64) (string, error) {
 	data, err := base64.StdEncoding.DecodeString(sharedSecret)
 	if err != nil {
 		return "", err
 	}
 
 	ful := make([]byte, 8)
-	binary.BigEndian.PutUint32(ful[4:], uint32(current/30))
+	binary.BigEndian.PutUint64(ful, uint64(timestamp/30))
 
 	hash := hmac.New(sha1.New, data)
 	_, err = hash.Write(ful)
@@ -72,6 +80,15 @@ func GenerateConfirmationCode(identitySecret, tag string, current int64) (string
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
 }
 
+// GenerateDeviceID returns the device ID the Steam mobile app derives from a SteamID,
+// in the "android:xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx" form expected by confirmations.
+func GenerateDeviceID(steamID SteamID) string {
+	sum := sha1.Sum([]byte(steamID.ToString()))
+	h := hex.EncodeToString(sum[:])
+
+	return "android:" + h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
 func GetTimeTip() (*ServerTimeTip, error) {
 	resp, err := http.Post(APIBaseUrl+"/ITwoFactorService/QueryTime/v1/", "application/x-www-form-urlencoded", nil)
 	if resp != nil {
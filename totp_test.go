@@ -0,0 +1,73 @@
+package steam
+
+import (
+	"strings"
+	"testing"
+)
+
+// rfc4226Secret is the secret of the RFC 4226 test vectors, base64 encoded.
+const rfc4226Secret = "MTIzNDU2Nzg5MDEyMzQ1Njc4OTA=" // "12345678901234567890"
+
+func TestGenerateTwoFactorCodeForTime(t *testing.T) {
+	// The truncated values are those of RFC 4226 appendix D, for the time steps 0 to 9,
+	// spelled in Steam's alphabet least significant digit first.  They cover offsets
+	// across the whole digest and digests whose truncated bytes have the high bit set.
+	tests := []struct {
+		timestamp int64
+		truncated uint32
+		want      string
+	}{
+		{0, 1284755224, "GG5F5"},
+		{30, 1094287082, "PV9M4"},
+		{60, 137359152, "B26KJ"},
+		{90, 1726969429, "5H85C"},
+		{120, 1640338314, "6Y9J3"},
+		{150, 868254676, "MD224"},
+		{180, 1918287922, "P2GRF"},
+		{210, 82162583, "C9PRW"},
+		{240, 673399871, "3NKKN"},
+		{299, 645520489, "5YCKB"},
+	}
+
+	for _, tt := range tests {
+		got, err := GenerateTwoFactorCodeForTime(rfc4226Secret, tt.timestamp)
+		if err != nil {
+			t.Fatalf("GenerateTwoFactorCodeForTime(%d): %v", tt.timestamp, err)
+		}
+
+		if got != tt.want {
+			t.Errorf("GenerateTwoFactorCodeForTime(%d) = %q, want %q (truncated value %d)", tt.timestamp, got, tt.want, tt.truncated)
+		}
+	}
+}
+
+func TestGenerateTwoFactorCodeAlphabet(t *testing.T) {
+	seen := map[rune]bool{}
+	for step := int64(0); step < 1000; step++ {
+		code, err := GenerateTwoFactorCode(rfc4226Secret, step*30)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(code) != 5 {
+			t.Fatalf("code %q at step %d is not 5 characters long", code, step)
+		}
+
+		for _, c := range code {
+			if !strings.ContainsRune(chars, c) {
+				t.Fatalf("code %q at step %d has %q, which is not in %q", code, step, c, chars)
+			}
+			seen[c] = true
+		}
+	}
+
+	if len(seen) != len(chars) {
+		t.Errorf("codes used %d characters out of %d", len(seen), len(chars))
+	}
+}
+
+func TestGenerateTwoFactorCodeInvalidSecret(t *testing.T) {
+	if _, err := GenerateTwoFactorCode("not base64!", 0); err == nil {
+		t.Error("expected an error for a secret that is not base64")
+	}
+}
@@ -1,6 +1,7 @@
 package steam
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -61,8 +62,13 @@ var (
 	ErrReceiptMatch        = errors.New("unable to match items in trade receipt")
 	ErrCannotAcceptActive  = errors.New("unable to accept a non-active trade")
 	ErrCannotFindOfferInfo = errors.New("unable to match data from trade offer url")
+	ErrTradeNotConfirmed   = errors.New("trade offer accepted but not confirmed")
 )
 
+// tradeConfirmationTimeout bounds how long AcceptTradeOfferAndConfirm waits for the
+// confirmation of an accepted offer to show up.
+const tradeConfirmationTimeout = time.Minute
+
 type EconItem struct {
 	AssetID    uint64 `json:"assetid,string,omitempty"`
 	InstanceID uint64 `json:"instanceid,string,omitempty"`
@@ -96,6 +102,7 @@ type EconItemDesc struct {
 	ClassID         uint64        `json:"classid,string"`    // for matching with EconItem
 	InstanceID      uint64        `json:"instanceid,string"` // for matching with EconItem
 	Tradable        int           `json:"tradable"`
+	Marketable      int           `json:"marketable"`
 	BackgroundColor string        `json:"background_color"`
 	IconURL         string        `json:"icon_url"`
 	IconLargeURL    string        `json:"icon_url_large"`
@@ -109,6 +116,99 @@ type EconItemDesc struct {
 	Actions         []*EconAction `json:"actions"`
 	Tags            []*EconTag    `json:"tags"`
 	Descriptions    []*EconDesc   `json:"descriptions"`
+
+	// Days an item is held before it can be traded or sold again, and the end of the
+	// current hold, if any, in RFC 3339 form.  See MarketableAt.
+	MarketTradableRestriction   int    `json:"market_tradable_restriction"`
+	MarketMarketableRestriction int    `json:"market_marketable_restriction"`
+	CacheExpiration             string `json:"cache_expiration"`
+}
+
+// UnmarshalJSON also reads Steam's "commodity" flag, sent as 0 or 1, into Comodity.
+func (desc *EconItemDesc) UnmarshalJSON(data []byte) error {
+	type plain EconItemDesc
+	aux := struct {
+		*plain
+		Commodity json.RawMessage `json:"commodity"`
+	}{plain: (*plain)(desc)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	switch strings.Trim(string(aux.Commodity), `"`) {
+	case "1", "true":
+		desc.Comodity = true
+	}
+
+	return nil
+}
+
+// IconURLFull returns the full URL of the item icon, see EconomyImageURL.
+func (desc *EconItemDesc) IconURLFull(size string) string {
+	return economyImageURL(desc.IconURL, size)
+}
+
+// MarketableAt returns when the item can be sold: zero if it can be now, the end of
+// its market hold otherwise.  Items that are never marketable report ok false.
+// It assumes Steam only sends the restrictions on the descriptions of items under a hold:
+// the hold then lasts the longest of them in days from now, or ends at CacheExpiration
+// when Steam sends it.
+func (desc *EconItemDesc) MarketableAt() (at time.Time, ok bool) {
+	if desc.Marketable == 0 {
+		return time.Time{}, false
+	}
+
+	days := max(desc.MarketTradableRestriction, desc.MarketMarketableRestriction)
+	if days <= 0 {
+		return time.Time{}, true
+	}
+
+	now := time.Now()
+	end := now.AddDate(0, 0, days)
+	if expiration, err := time.Parse(time.RFC3339, desc.CacheExpiration); err == nil {
+		end = expiration
+	}
+
+	if !end.After(now) {
+		return time.Time{}, true
+	}
+
+	return end, true
+}
+
+// Tag returns the first tag of the given category, e.g. "Exterior", "Rarity" or "Quality".
+func (desc *EconItemDesc) Tag(category string) (*EconTag, bool) {
+	for _, tag := range desc.Tags {
+		if tag.Category == category {
+			return tag, true
+		}
+	}
+
+	return nil, false
+}
+
+func (desc *EconItemDesc) tagName(category string) string {
+	if tag, ok := desc.Tag(category); ok {
+		return tag.LocalizedTagName
+	}
+
+	return ""
+}
+
+// Exterior returns the localized exterior (wear) of the item or an empty string.
+func (desc *EconItemDesc) Exterior() string {
+	return desc.tagName("Exterior")
+}
+
+// Rarity returns the localized rarity of the item or an empty string.
+func (desc *EconItemDesc) Rarity() string {
+	return desc.tagName("Rarity")
+}
+
+// Quality returns the localized quality of the item or an empty string.
+func (desc *EconItemDesc) Quality() string {
+	return desc.tagName("Quality")
 }
 
 type TradeOffer struct {
@@ -140,7 +240,7 @@ type APIResponse struct {
 }
 
 func (session *Session) GetTradeOffer(id uint64) (*TradeOffer, error) {
-	resp, err := session.client.Get(apiGetTradeOffer + url.Values{
+	resp, err := session.httpClient().Get(apiGetTradeOffer + url.Values{
 		"key":          {session.apiKey},
 		"tradeofferid": {strconv.FormatUint(id, 10)},
 	}.Encode())
@@ -165,49 +265,81 @@ func testBit(bits uint32, bit uint32) bool {
 }
 
 func (session *Session) GetTradeOffers(filter uint32, timeCutOff time.Time) (*TradeOfferResponse, error) {
+	return session.GetTradeOffersWithFilter("", TradeOfferFilter{
+		Sent:             testBit(filter, TradeFilterSentOffers),
+		Received:         testBit(filter, TradeFilterRecvOffers),
+		ActiveOnly:       testBit(filter, TradeFilterActiveOnly),
+		HistoricalOnly:   testBit(filter, TradeFilterHistoricalOnly),
+		Descriptions:     testBit(filter, TradeFilterItemDescriptions),
+		HistoricalCutoff: timeCutOff,
+	})
+}
+
+// TradeOfferFilter selects the offers returned by GetTradeOffersWithFilter.
+type TradeOfferFilter struct {
+	Sent             bool
+	Received         bool
+	ActiveOnly       bool
+	HistoricalOnly   bool
+	HistoricalCutoff time.Time // Only used with HistoricalOnly
+	Descriptions     bool      // Fills TradeOfferResponse.Descriptions
+	Language         string    // Of the descriptions, the session language when empty
+}
+
+// GetTradeOffersWithFilter lists the offers matching filter through IEconService/GetTradeOffers,
+// using apiKey, or the session's key when empty.
+func (session *Session) GetTradeOffersWithFilter(apiKey string, filter TradeOfferFilter) (*TradeOfferResponse, error) {
+	if apiKey == "" {
+		apiKey = session.apiKey
+	}
+
+	if apiKey == "" {
+		return nil, ErrMissingAPIKey
+	}
+
 	params := url.Values{
-		"key": {session.apiKey},
+		"key": {apiKey},
 	}
-	if testBit(filter, TradeFilterSentOffers) {
+	if filter.Sent {
 		params.Set("get_sent_offers", "1")
 	}
 
-	if testBit(filter, TradeFilterRecvOffers) {
+	if filter.Received {
 		params.Set("get_received_offers", "1")
 	}
 
-	if testBit(filter, TradeFilterActiveOnly) {
+	if filter.ActiveOnly {
 		params.Set("active_only", "1")
 	}
 
-	if testBit(filter, TradeFilterItemDescriptions) {
+	if filter.Descriptions {
 		params.Set("get_descriptions", "1")
+		if filter.Language != "" {
+			params.Set("language", filter.Language)
+		} else {
+			params.Set("language", session.language)
+		}
 	}
 
-	if testBit(filter, TradeFilterHistoricalOnly) {
+	if filter.HistoricalOnly {
 		params.Set("historical_only", "1")
-		params.Set("time_historical_cutoff", strconv.FormatInt(timeCutOff.Unix(), 10))
-	}
-
-	resp, err := session.client.Get(apiGetTradeOffers + params.Encode())
-	if resp != nil {
-		defer resp.Body.Close()
+		params.Set("time_historical_cutoff", strconv.FormatInt(filter.HistoricalCutoff.Unix(), 10))
 	}
 
-	if err != nil {
+	var response APIResponse
+	if err := session.getJSON(context.Background(), http.MethodGet, apiGetTradeOffers+params.Encode(), nil, "", &response); err != nil {
 		return nil, err
 	}
 
-	var response APIResponse
-	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, err
+	if response.Inner == nil {
+		return &TradeOfferResponse{}, nil
 	}
 
 	return response.Inner, nil
 }
 
 func (session *Session) GetMyTradeToken() (string, error) {
-	resp, err := session.client.Get("https://steamcommunity.com/my/tradeoffers/privacy")
+	resp, err := session.httpClient().Get("https://steamcommunity.com/my/tradeoffers/privacy")
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -246,12 +378,30 @@ func (session *Session) GetEscrowGuardInfo(sid SteamID, token string) (*EscrowSt
 	}.Encode())
 }
 
+// GetTradeHoldDuration returns how long items would be held, in seconds, when trading
+// with partner: items we give and items they give.  token is the partner's trade offer
+// access token, empty for friends.  The error Steam shows instead of the offer page,
+// e.g. for a partner that cannot trade, is returned as is.
+func (session *Session) GetTradeHoldDuration(partner SteamID, token string) (mySeconds, theirSeconds int64, err error) {
+	info, err := session.GetEscrowGuardInfo(partner, token)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if info.ErrorMsg != "" {
+		return 0, 0, errors.New(strings.TrimSpace(info.ErrorMsg))
+	}
+
+	day := int64(24 * time.Hour / time.Second)
+	return info.MyDays * day, info.ThemDays * day, nil
+}
+
 func (session *Session) GetEscrowGuardInfoForTrade(offerID uint64) (*EscrowSteamGuardInfo, error) {
 	return session.GetEscrow("https://steamcommunity.com/tradeoffer/" + strconv.FormatUint(offerID, 10))
 }
 
 func (session *Session) GetEscrow(url string) (*EscrowSteamGuardInfo, error) {
-	resp, err := session.client.Get(url)
+	resp, err := session.httpClient().Get(url)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -337,7 +487,7 @@ func (session *Session) SendTradeOffer(offer *TradeOffer, sid SteamID, token str
 	}.Encode())
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := session.client.Do(req)
+	resp, err := session.httpClient().Do(req)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -386,8 +536,43 @@ func (session *Session) SendTradeOffer(offer *TradeOffer, sid SteamID, token str
 	return nil
 }
 
+// SendTradeOfferItems offers partner the give items in exchange for the receive ones,
+// token is the partner's trade offer access token, empty for friends.
+// The offer sent is returned, with its ID and state set.
+func (session *Session) SendTradeOfferItems(partner SteamID, token string, give, receive []InventoryItem, message string) (*TradeOffer, error) {
+	offer := &TradeOffer{
+		Partner:   partner.GetAccountID(),
+		SendItems: econItems(give),
+		RecvItems: econItems(receive),
+		Message:   message,
+	}
+
+	if err := session.SendTradeOffer(offer, partner, token); err != nil {
+		return nil, err
+	}
+
+	return offer, nil
+}
+
+// econItems converts inventory items to the asset form trade offers use.
+func econItems(items []InventoryItem) []*EconItem {
+	assets := make([]*EconItem, len(items))
+	for i, item := range items {
+		assets[i] = &EconItem{
+			AssetID:    item.AssetID,
+			InstanceID: item.InstanceID,
+			ClassID:    item.ClassID,
+			AppID:      item.AppID,
+			ContextID:  item.ContextID,
+			Amount:     uint32(item.Amount),
+		}
+	}
+
+	return assets
+}
+
 func (session *Session) GetTradeReceivedItems(receiptID uint64) ([]*InventoryItem, error) {
-	resp, err := session.client.Get(fmt.Sprintf("https://steamcommunity.com/trade/%d/receipt", receiptID))
+	resp, err := session.httpClient().Get(fmt.Sprintf("https://steamcommunity.com/trade/%d/receipt", receiptID))
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -423,8 +608,14 @@ func (session *Session) GetTradeReceivedItems(receiptID uint64) ([]*InventoryIte
 	return items, nil
 }
 
+// DeclineTradeOffer declines the offer id through the Web API, or through
+// the community site like a browser does when the session has no API key.
 func (session *Session) DeclineTradeOffer(id uint64) error {
-	resp, err := session.client.PostForm(apiDeclineTradeOffer, url.Values{
+	if session.apiKey == "" {
+		return session.declineTradeOfferCommunity(id)
+	}
+
+	resp, err := session.httpClient().PostForm(apiDeclineTradeOffer, url.Values{
 		"key":          {session.apiKey},
 		"tradeofferid": {strconv.FormatUint(id, 10)},
 	})
@@ -444,8 +635,30 @@ func (session *Session) DeclineTradeOffer(id uint64) error {
 	return nil
 }
 
+func (session *Session) declineTradeOfferCommunity(id uint64) error {
+	tid := strconv.FormatUint(id, 10)
+	postURL := "https://steamcommunity.com/tradeoffer/" + tid
+
+	type Response struct {
+		ID uint64 `json:"tradeofferid,string"`
+	}
+
+	var response Response
+	if err := session.getJSON(context.Background(), http.MethodPost, postURL+"/decline", url.Values{
+		"sessionid": {session.sessionID},
+	}, postURL, &response); err != nil {
+		return err
+	}
+
+	if response.ID != id {
+		return fmt.Errorf("cannot decline trade offer %d", id)
+	}
+
+	return nil
+}
+
 func (session *Session) CancelTradeOffer(id uint64) error {
-	resp, err := session.client.PostForm(apiCancelTradeOffer, url.Values{
+	resp, err := session.httpClient().PostForm(apiCancelTradeOffer, url.Values{
 		"key":          {session.apiKey},
 		"tradeofferid": {strconv.FormatUint(id, 10)},
 	})
@@ -466,49 +679,99 @@ func (session *Session) CancelTradeOffer(id uint64) error {
 }
 
 func (session *Session) AcceptTradeOffer(id uint64) error {
+	_, err := session.acceptTradeOffer(id, 0)
+	return err
+}
+
+// acceptTradeOffer accepts the offer id from partner, which may be zero when unknown,
+// and reports whether the acceptance waits for a mobile confirmation.
+func (session *Session) acceptTradeOffer(id uint64, partner SteamID) (bool, error) {
 	tid := strconv.FormatUint(id, 10)
 	postURL := "https://steamcommunity.com/tradeoffer/" + tid
 
-	req, err := http.NewRequest(
-		http.MethodPost,
-		postURL+"/accept",
-		strings.NewReader(url.Values{
-			"sessionid":    {session.sessionID},
-			"serverid":     {"1"},
-			"tradeofferid": {tid},
-		}.Encode()),
-	)
-	if err != nil {
-		return err
+	form := url.Values{
+		"sessionid":    {session.sessionID},
+		"serverid":     {"1"},
+		"tradeofferid": {tid},
+	}
+	if partner != 0 {
+		form.Set("partner", partner.ToString())
 	}
 
-	req.Header.Add("Referer", postURL)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	type Response struct {
+		ErrorMessage               string `json:"strError"`
+		MobileConfirmationRequired bool   `json:"needs_mobile_confirmation"`
+	}
 
-	resp, err := session.client.Do(req)
-	if resp != nil {
-		defer resp.Body.Close()
+	var response Response
+	if err := session.getJSON(context.Background(), http.MethodPost, postURL+"/accept", form, postURL, &response); err != nil {
+		return false, err
 	}
 
+	if len(response.ErrorMessage) != 0 {
+		return false, errors.New(response.ErrorMessage)
+	}
+
+	return response.MobileConfirmationRequired, nil
+}
+
+// AcceptTradeOfferAndConfirm accepts the offer id from partner and, when Steam asks for it,
+// accepts its mobile confirmation with identitySecret.  The resulting trade state is returned,
+// as reported by GetTradeOffer when the session has a Web API key, TradeStateAccepted otherwise.
+// If the offer was accepted but could not be confirmed, the error wraps ErrTradeNotConfirmed
+// and the state is the one GetTradeOffer reports, TradeStateNone without a Web API key.
+func (session *Session) AcceptTradeOfferAndConfirm(id uint64, partner SteamID, identitySecret string) (uint8, error) {
+	needsConfirmation, err := session.acceptTradeOffer(id, partner)
 	if err != nil {
-		return err
+		return TradeStateNone, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("http error: %d", resp.StatusCode)
+	if needsConfirmation {
+		if err := session.confirmTradeOffer(id, identitySecret); err != nil {
+			state := uint8(TradeStateNone)
+			if session.apiKey != "" {
+				if offer, err := session.GetTradeOffer(id); err == nil {
+					state = offer.State
+				}
+			}
+
+			return state, fmt.Errorf("%w: %w", ErrTradeNotConfirmed, err)
+		}
 	}
 
-	type Response struct {
-		ErrorMessage string `json:"strError"`
+	if session.apiKey == "" {
+		return TradeStateAccepted, nil
 	}
 
-	var response Response
-	if err = json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	offer, err := session.GetTradeOffer(id)
+	if err != nil {
+		return TradeStateAccepted, err
+	}
+
+	return offer.State, nil
+}
+
+// confirmTradeOffer waits for the confirmation of the offer id to show up and accepts it,
+// as it may take Steam a few seconds to create it once the offer is accepted.
+func (session *Session) confirmTradeOffer(id uint64, identitySecret string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), tradeConfirmationTimeout)
+	defer cancel()
+
+	creator := strconv.FormatUint(id, 10)
+	conf, err := session.WaitForConfirmation(ctx, identitySecret, func(conf *Confirmation) bool {
+		return conf.Type == ConfirmationTypeTrade && conf.Creator == creator
+	})
+	if err != nil {
 		return err
 	}
 
-	if len(response.ErrorMessage) != 0 {
-		return errors.New(response.ErrorMessage)
+	result, err := session.SendConfirmationAjax(conf, ConfirmationAccept, identitySecret)
+	if err != nil {
+		return err
+	}
+
+	if !result.Success {
+		return fmt.Errorf("cannot confirm trade offer %d", id)
 	}
 
 	return nil
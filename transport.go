@@ -0,0 +1,243 @@
+package steam
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// RequestHook is called after every request the session sends,
+// resp is nil when err is not.  It must not read or close resp.Body.
+type RequestHook func(req *http.Request, resp *http.Response, err error)
+
+// SetRequestHook registers hook to observe every request, e.g. for logging or metrics.
+// A nil hook removes it.
+func (session *Session) SetRequestHook(hook RequestHook) {
+	session.transportMu.Lock()
+	defer session.transportMu.Unlock()
+
+	session.hook = hook
+	session.installTransport()
+}
+
+// SetTransport replaces the http.RoundTripper the session's requests are sent with,
+// rate limiting and hooks still apply on top of it.  A proxy provider can only be
+// applied to an *http.Transport, so it is removed when rt is another RoundTripper.
+func (session *Session) SetTransport(rt http.RoundTripper) {
+	session.transportMu.Lock()
+	defer session.transportMu.Unlock()
+
+	t := session.installTransport()
+	t.base = rt
+
+	proxied, err := proxiedTransport(rt, session.proxyProvider)
+	if err != nil {
+		session.proxyProvider = nil
+	}
+	t.proxied = proxied
+}
+
+// SetDebugWriter dumps the method, URL, status and full body of every response to w,
+// to diagnose responses Steam changed the shape of.  A nil w stops it.
+func (session *Session) SetDebugWriter(w io.Writer) {
+	session.transportMu.Lock()
+	defer session.transportMu.Unlock()
+
+	session.debugWriter = w
+	session.installTransport()
+}
+
+// dumpResponse writes resp to the debug writer, if any, and puts back a body
+// holding the same bytes.
+func (session *Session) dumpResponse(req *http.Request, resp *http.Response) error {
+	session.transportMu.Lock()
+	w := session.debugWriter
+	session.transportMu.Unlock()
+
+	if w == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	session.debugMu.Lock()
+	defer session.debugMu.Unlock()
+
+	fmt.Fprintf(w, "%s %s: %s\n%s\n\n", req.Method, req.URL, resp.Status, body)
+	return nil
+}
+
+// ProxyProvider picks the proxy every request is sent through, a nil URL sends it directly.
+type ProxyProvider interface {
+	Proxy(req *http.Request) (*url.URL, error)
+}
+
+// ProxyProviderFunc adapts a function to a ProxyProvider.
+type ProxyProviderFunc func(req *http.Request) (*url.URL, error)
+
+func (f ProxyProviderFunc) Proxy(req *http.Request) (*url.URL, error) {
+	return f(req)
+}
+
+// RoundRobinProxies returns a ProxyProvider cycling through proxies, one request each.
+func RoundRobinProxies(proxies ...*url.URL) ProxyProvider {
+	var mu sync.Mutex
+	next := 0
+
+	return ProxyProviderFunc(func(req *http.Request) (*url.URL, error) {
+		if len(proxies) == 0 {
+			return nil, nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		proxy := proxies[next]
+		next = (next + 1) % len(proxies)
+		return proxy, nil
+	})
+}
+
+// ErrProxyUnsupported is returned by SetProxyProvider when the session's transport
+// is a RoundTripper the proxy cannot be applied to.
+var ErrProxyUnsupported = errors.New("proxy provider needs the session transport to be an *http.Transport")
+
+// SetProxyProvider sends every request through the proxy provider returns for it,
+// cookies are still shared whatever the proxy.  It is applied to a copy of the transport
+// set by SetTransport, or of http.DefaultTransport if none, which must be an *http.Transport:
+// ErrProxyUnsupported is returned otherwise.  A nil provider removes it.
+func (session *Session) SetProxyProvider(provider ProxyProvider) error {
+	session.transportMu.Lock()
+	defer session.transportMu.Unlock()
+
+	t := session.installTransport()
+	proxied, err := proxiedTransport(t.base, provider)
+	if err != nil {
+		return err
+	}
+
+	session.proxyProvider = provider
+	t.proxied = proxied
+	return nil
+}
+
+func proxiedTransport(base http.RoundTripper, provider ProxyProvider) (http.RoundTripper, error) {
+	if provider == nil {
+		return nil, nil
+	}
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return nil, ErrProxyUnsupported
+	}
+
+	transport = transport.Clone()
+	transport.Proxy = provider.Proxy
+	return transport, nil
+}
+
+func (session *Session) runHook(req *http.Request, resp *http.Response, err error) {
+	session.transportMu.Lock()
+	hook := session.hook
+	session.transportMu.Unlock()
+
+	if hook != nil {
+		hook(req, resp, err)
+	}
+}
+
+// sessionTransport wraps the client's transport to apply the session's
+// per-request behaviour, whatever helper issued the request.
+type sessionTransport struct {
+	session *Session
+	base    http.RoundTripper
+	proxied http.RoundTripper // base with the session's proxy provider, if any
+}
+
+func (t *sessionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if lang := acceptLanguage(t.session.language); lang != "" && req.Header.Get("Accept-Language") == "" {
+		/* A RoundTripper must not modify the request it is given.  */
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept-Language", lang)
+	}
+
+	if limiter := t.session.rateLimiter(req.URL.Host); limiter != nil {
+		if err := limiter.Wait(req.Context()); err != nil {
+			t.session.runHook(req, nil, err)
+			return nil, err
+		}
+	}
+
+	t.session.transportMu.Lock()
+	base := t.base
+	if t.proxied != nil {
+		base = t.proxied
+	}
+	t.session.transportMu.Unlock()
+
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err == nil {
+		if err = t.session.dumpResponse(req, resp); err != nil {
+			resp = nil
+		}
+	}
+	t.session.runHook(req, resp, err)
+
+	return resp, err
+}
+
+// installTransport returns the session's transport, wrapping the client's transport
+// again if it was replaced since.  It must be called with transportMu held.
+func (session *Session) installTransport() *sessionTransport {
+	client := session.httpClient()
+	if t, ok := client.Transport.(*sessionTransport); ok && t.session == session {
+		return t
+	}
+
+	t := session.wrapClient(client)
+	if proxied, err := proxiedTransport(t.base, session.proxyProvider); err == nil {
+		t.proxied = proxied
+	} else {
+		session.proxyProvider = nil
+	}
+	return t
+}
+
+// wrapClient switches the session to a copy of client whose transport wraps the original one.
+// The client given to the session may be shared, e.g. http.DefaultClient or the client
+// of another session, so it is left untouched, cookies are still shared through its jar.
+func (session *Session) wrapClient(client *http.Client) *sessionTransport {
+	base := client.Transport
+	if t, ok := base.(*sessionTransport); ok {
+		/* Another session's settings must not apply to this one.  */
+		base = t.base
+	}
+
+	t := &sessionTransport{
+		session: session,
+		base:    base,
+	}
+
+	wrapped := *client
+	wrapped.Transport = t
+	session.client = &wrapped
+
+	return t
+}
@@ -0,0 +1,51 @@
+package steam
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type stubRoundTripper struct {
+	calls int
+}
+
+func (rt *stubRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.calls++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader("{}")),
+		Request:    req,
+	}, nil
+}
+
+func TestSetProxyProviderCustomTransport(t *testing.T) {
+	proxy, _ := url.Parse("http://127.0.0.1:1")
+
+	session := NewSession(nil, "")
+	rt := &stubRoundTripper{}
+	session.SetTransport(rt)
+
+	if err := session.SetProxyProvider(RoundRobinProxies(proxy)); !errors.Is(err, ErrProxyUnsupported) {
+		t.Fatalf("SetProxyProvider with a custom transport = %v, want ErrProxyUnsupported", err)
+	}
+
+	resp, err := session.httpClient().Get("https://steamcommunity.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if rt.calls != 1 {
+		t.Errorf("the custom transport got %d requests, want 1", rt.calls)
+	}
+
+	session.SetTransport(&http.Transport{})
+	if err := session.SetProxyProvider(RoundRobinProxies(proxy)); err != nil {
+		t.Errorf("SetProxyProvider with an *http.Transport = %v", err)
+	}
+}
@@ -34,7 +34,7 @@ const (
 var ErrCannotDisable = errors.New("unable to process disable two factor request")
 
 func (session *Session) EnableTwoFactor() (*TwoFactorInfo, error) {
-	resp, err := session.client.PostForm(enableTwoFactorURL, url.Values{
+	resp, err := session.httpClient().PostForm(enableTwoFactorURL, url.Values{
 		"steamid":            {session.oauth.SteamID.ToString()},
 		"access_token":       {session.oauth.Token},
 		"authenticator_time": {strconv.FormatInt(time.Now().Unix(), 10)},
@@ -63,7 +63,7 @@ func (session *Session) EnableTwoFactor() (*TwoFactorInfo, error) {
 }
 
 func (session *Session) FinalizeTwoFactor(authCode, mobileCode string) (*FinalizeTwoFactorInfo, error) {
-	resp, err := session.client.PostForm(finalizeTwoFactorURL, url.Values{
+	resp, err := session.httpClient().PostForm(finalizeTwoFactorURL, url.Values{
 		"steamid":            {session.oauth.SteamID.ToString()},
 		"access_token":       {session.oauth.Token},
 		"authenticator_time": {strconv.FormatInt(time.Now().Unix(), 10)},
@@ -91,7 +91,7 @@ func (session *Session) FinalizeTwoFactor(authCode, mobileCode string) (*Finaliz
 }
 
 func (session *Session) DisableTwoFactor(revocationCode string) error {
-	resp, err := session.client.PostForm(disableTwoFactorURL, url.Values{
+	resp, err := session.httpClient().PostForm(disableTwoFactorURL, url.Values{
 		"steamid":           {session.oauth.SteamID.ToString()},
 		"access_token":      {session.oauth.Token},
 		"revocation_code":   {revocationCode},
@@ -48,8 +48,18 @@ func (session *Session) parseKey(resp *http.Response) (string, error) {
 	return submatch[1], nil
 }
 
+// SetAPIKey sets the Web API key used by the methods calling api.steampowered.com.
+func (session *Session) SetAPIKey(apiKey string) {
+	session.apiKey = apiKey
+}
+
+// APIKey returns the session's Web API key, empty if none is set.
+func (session *Session) APIKey() string {
+	return session.apiKey
+}
+
 func (session *Session) RegisterWebAPIKey(domain string) (string, error) {
-	resp, err := session.client.PostForm(apiKeyRegisterURL, url.Values{
+	resp, err := session.httpClient().PostForm(apiKeyRegisterURL, url.Values{
 		"domain":       {domain},
 		"agreeToTerms": {"agreed"},
 		"sessionid":    {session.sessionID},
@@ -71,7 +81,7 @@ func (session *Session) RegisterWebAPIKey(domain string) (string, error) {
 }
 
 func (session *Session) GetWebAPIKey() (string, error) {
-	resp, err := session.client.Get(apiKeyURL)
+	resp, err := session.httpClient().Get(apiKeyURL)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
@@ -84,7 +94,7 @@ func (session *Session) GetWebAPIKey() (string, error) {
 }
 
 func (session *Session) RevokeWebAPIKey() error {
-	resp, err := session.client.PostForm(apiKeyRevokeURL, url.Values{
+	resp, err := session.httpClient().PostForm(apiKeyRevokeURL, url.Values{
 		"Revoke":    {"Revoke My Steam Web API Key"},
 		"sessionid": {session.sessionID},
 	})